@@ -10,7 +10,6 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -43,7 +42,7 @@ func makeKeyHostname(v url.Values) string {
 	if parseErr != nil {
 		log.Print(parseErr)
 	}
-	return strings.ReplaceAll(urlValue.Hostname(), ".", "_")
+	return hostDirectory(urlValue.Hostname())
 }
 
 func makeValue(v url.Values) string {
@@ -0,0 +1,307 @@
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const (
+	// sessionIDParameter is the Boomerang session id parameter
+	sessionIDParameter = "rt.si"
+	// pageIDParameter is the Boomerang page id parameter
+	pageIDParameter = "pid"
+	// openHourGrace is the time after the end of the hour when the hour file can still be appended by the last flush
+	openHourGrace = time.Minute
+	tempSuffix    = ".tmp"
+	// dayLayout is the date format of the backup day directories and files, see dateUTC
+	dayLayout = "2006-1-2"
+)
+
+// ArchiveDirectory returns the directory of the request archive in the backup directory
+func ArchiveDirectory(baseDirectory string) string {
+	return filepath.Join(baseDirectory, string(archive))
+}
+
+// Erase removes the lines of the erased sessions and page views from all hostname backup files
+// The days are found in the backup directory, so the erasure does not depend on the database rows which are already deleted.
+// Both the hourly files and the archived day files with their meta are rewritten, it returns the number of the removed lines.
+// The hour files which the backup can still append at now are skipped, the erasure is repeated after the hour ends
+func Erase(backupRootDir string, request types.ErasureRequest, now time.Time) (int, error) {
+	matcher := request.Matcher()
+	hosts, err := os.ReadDir(backupRootDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot read directory[%v] err[%w]", backupRootDir, err)
+	}
+	removed := 0
+	for _, host := range hosts {
+		if !host.IsDir() {
+			continue
+		}
+		days, err := backupDays(filepath.Join(backupRootDir, host.Name()))
+		if err != nil {
+			return removed, err
+		}
+		for _, day := range days {
+			count, err := eraseDay(backupRootDir, host.Name(), day, matcher, now)
+			removed += count
+			if err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
+
+// backupDays finds the days of the hourly directories and the archived day files of the host directory
+func backupDays(hostPath string) ([]time.Time, error) {
+	entries, err := os.ReadDir(hostPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read directory[%v] err[%w]", hostPath, err)
+	}
+	seen := make(map[time.Time]bool)
+	var result []time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() {
+			var ok bool
+			if name, ok = archivedDayName(name); !ok {
+				continue
+			}
+		}
+		day, err := time.Parse(dayLayout, name)
+		if err != nil || seen[day] {
+			continue
+		}
+		seen[day] = true
+		result = append(result, day)
+	}
+	return result, nil
+}
+
+// archivedDayName returns the date of the archived day file name
+func archivedDayName(name string) (string, bool) {
+	for _, suffix := range []string{".json.lines", ".json.lines.gz", ".json.lines.zst"} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix), true
+		}
+	}
+	return "", false
+}
+
+func eraseDay(backupRootDir, host string, day time.Time, matcher types.ErasureMatcher, now time.Time) (int, error) {
+	removed, err := eraseHours(makeDayPath(backupRootDir, host, day), day, matcher, now)
+	if err != nil {
+		return removed, err
+	}
+	archived, err := eraseArchivedDay(backupRootDir, host, day, matcher)
+	return removed + archived, err
+}
+
+func hostDirectory(hostname string) string {
+	return strings.ReplaceAll(hostname, ".", "_")
+}
+
+func eraseHours(datePath string, day time.Time, matcher types.ErasureMatcher, now time.Time) (int, error) {
+	removed := 0
+	for hour := 0; hour < 24; hour++ {
+		if isOpenHour(dayWithHour(day, hour), now) {
+			continue
+		}
+		lines, err := readFileByHour(datePath, dayWithHour(day, hour))
+		if err != nil {
+			return removed, err
+		}
+		kept, count := filterLines(lines, matcher)
+		if count == 0 {
+			continue
+		}
+		hourPath := makeHourPath(datePath, dayWithHour(day, hour))
+		if err := replaceFile(hourPath, kept, newNoneFactory()); err != nil {
+			return removed, err
+		}
+		removed += count
+	}
+	return removed, nil
+}
+
+// isOpenHour checks if the backup can still append to the hour file
+func isOpenHour(hour, now time.Time) bool {
+	return now.Before(hour.Add(time.Hour + openHourGrace))
+}
+
+// replaceFile writes the content to temp file and renames it, so the file is not lost when the write fails
+func replaceFile(filename, content string, factory CompressionWriterFactory) error {
+	tempFilename := filename + tempSuffix
+	if err := writeToFile(tempFilename, content, factory); err != nil {
+		return fmt.Errorf("cannot write file[%v] err[%w]", factory.Filename(tempFilename), err)
+	}
+	if err := os.Rename(factory.Filename(tempFilename), factory.Filename(filename)); err != nil {
+		return fmt.Errorf("cannot replace file[%v] err[%w]", factory.Filename(filename), err)
+	}
+	return nil
+}
+
+func eraseArchivedDay(backupRootDir, host string, day time.Time, matcher types.ErasureMatcher) (int, error) {
+	archiveDayPath := makeArchiveDayPath(backupRootDir, host, day)
+	factory, err := findArchivedDay(archiveDayPath)
+	if err != nil || factory == nil {
+		return 0, err
+	}
+	content, err := readCompressed(factory.Filename(archiveDayPath))
+	if err != nil {
+		return 0, fmt.Errorf("cannot read file[%v] err[%w]", factory.Filename(archiveDayPath), err)
+	}
+	metaPath := makeArchiveDayMetaPath(backupRootDir, host, day)
+	meta, err := readAll(metaPath)
+	metaExists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("cannot read file[%v] err[%w]", metaPath, err)
+	}
+
+	dayContent, summary, removed, err := filterArchivedDay(content, string(meta), matcher)
+	if err != nil || removed == 0 {
+		return 0, err
+	}
+	if err := replaceFile(archiveDayPath, dayContent, factory); err != nil {
+		return 0, err
+	}
+	if metaExists {
+		if err := replaceFile(metaPath, summary, newNoneFactory()); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// findArchivedDay finds the compression of the archived day file, it returns nil when the file does not exist
+func findArchivedDay(archiveDayPath string) (CompressionWriterFactory, error) {
+	factories := []CompressionWriterFactory{
+		newNoneFactory(),
+		newGZIPFactory(DefaultCompressionLevel),
+		newZStdFactory(DefaultCompressionLevel),
+	}
+	for _, factory := range factories {
+		_, err := os.Stat(factory.Filename(archiveDayPath))
+		if err == nil {
+			return factory, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	// nolint: nilnil
+	return nil, nil
+}
+
+func readCompressed(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var reader io.Reader = f
+	switch filepath.Ext(filename) {
+	case ".gz":
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return "", err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case ".zst":
+		zstdReader, err := zstd.NewReader(f)
+		if err != nil {
+			return "", err
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	}
+	content, err := io.ReadAll(reader)
+	return string(content), err
+}
+
+// filterArchivedDay removes the erased lines from the day content and recalculates the hourly meta lines
+// The meta line is "hour,first line,last line" with lines numbered from 1
+func filterArchivedDay(content, meta string, matcher types.ErasureMatcher) (string, string, int, error) {
+	if meta == "" {
+		kept, removed := filterLines(content, matcher)
+		return kept, "", removed, nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	var dayContent, summary strings.Builder
+	removed, total := 0, 0
+	for _, metaLine := range strings.Split(strings.TrimSpace(meta), "\n") {
+		hour, first, last, err := parseMetaLine(metaLine)
+		if err != nil {
+			return "", "", 0, err
+		}
+		if first < 1 || first > last || last > len(lines) {
+			return "", "", 0, fmt.Errorf("invalid meta line[%v] lines[%v]", metaLine, len(lines))
+		}
+		kept, count := filterLines(strings.Join(lines[first-1:last], ""), matcher)
+		removed += count
+		linesCount := countLines(kept)
+		if linesCount == 0 {
+			continue
+		}
+		dayContent.WriteString(kept)
+		summary.WriteString(fmt.Sprintf("%v,%v,%v\n", hour, total+1, total+linesCount))
+		total += linesCount
+	}
+	return dayContent.String(), summary.String(), removed, nil
+}
+
+func parseMetaLine(line string) (int, int, int, error) {
+	parts := strings.Split(line, ",")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid meta line[%v]", line)
+	}
+	var values [3]int
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid meta line[%v] err[%w]", line, err)
+		}
+		values[i] = value
+	}
+	return values[0], values[1], values[2], nil
+}
+
+// filterLines removes the erased JSON lines, it returns the kept lines and the number of the removed lines
+func filterLines(lines string, matcher types.ErasureMatcher) (string, int) {
+	var result strings.Builder
+	removed := 0
+	for _, line := range strings.SplitAfter(lines, "\n") {
+		if matchesErasure(line, matcher) {
+			removed++
+			continue
+		}
+		result.WriteString(line)
+	}
+	return result.String(), removed
+}
+
+func matchesErasure(line string, matcher types.ErasureMatcher) bool {
+	if !strings.Contains(line, sessionIDParameter) && !strings.Contains(line, pageIDParameter) {
+		return false
+	}
+	var params map[string]string
+	if err := json.Unmarshal([]byte(line), &params); err != nil {
+		return false
+	}
+	return matcher.Matches(params[sessionIDParameter], params[pageIDParameter])
+}
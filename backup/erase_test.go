@@ -0,0 +1,130 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	erasedLine = `{"rt.si":"session1","u":"https://www.example.com/"}` + "\n"
+	keptLine   = `{"rt.si":"session2","u":"https://www.example.com/"}` + "\n"
+)
+
+func TestErase(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression Compression
+	}{
+		{
+			name:        "none",
+			compression: NoneCompression,
+		},
+		{
+			name:        "gzip",
+			compression: GZIPCompression,
+		},
+		{
+			name:        "zstd",
+			compression: ZStandardCompression,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			rootDir := t.TempDir()
+			archivedDay := day(2023, 9, 20)
+			currentDay := day(2023, 9, 21)
+			factory := NewCompressionWriterFactory(true, tt.compression, DefaultCompressionLevel)
+			require.NoError(t, writeDayFile(rootDir, "www_example_com", archivedDay, erasedLine+keptLine+erasedLine+erasedLine+keptLine, factory))
+			require.NoError(t, writeHourlySummary(rootDir, "www_example_com", archivedDay, "1,1,2\n5,3,3\n7,4,5\n"))
+			hourPath := makeHourPath(makeDayPath(rootDir, "www_example_com", currentDay), dayWithHour(currentDay, 3))
+			require.NoError(t, os.MkdirAll(filepath.Dir(hourPath), os.ModePerm))
+			require.NoError(t, os.WriteFile(hourPath, []byte(keptLine+erasedLine), os.ModePerm))
+
+			otherHourPath := makeHourPath(makeDayPath(rootDir, "www_example_org", currentDay), dayWithHour(currentDay, 4))
+			require.NoError(t, os.MkdirAll(filepath.Dir(otherHourPath), os.ModePerm))
+			require.NoError(t, os.WriteFile(otherHourPath, []byte(erasedLine+keptLine), os.ModePerm))
+
+			// when
+			removed, err := Erase(rootDir, types.ErasureRequest{SessionIDs: []string{"session1"}}, currentDay.AddDate(0, 0, 1))
+
+			// then
+			require.NoError(t, err)
+			require.Equal(t, 5, removed)
+			archiveDayPath := factory.Filename(makeArchiveDayPath(rootDir, "www_example_com", archivedDay))
+			content, err := readCompressed(archiveDayPath)
+			require.NoError(t, err)
+			require.Equal(t, keptLine+keptLine, content)
+			meta, err := os.ReadFile(makeArchiveDayMetaPath(rootDir, "www_example_com", archivedDay))
+			require.NoError(t, err)
+			require.Equal(t, "1,1,1\n7,2,2\n", string(meta))
+			hourContent, err := os.ReadFile(hourPath)
+			require.NoError(t, err)
+			require.Equal(t, keptLine, string(hourContent))
+			otherHourContent, err := os.ReadFile(otherHourPath)
+			require.NoError(t, err)
+			require.Equal(t, keptLine, string(otherHourContent))
+		})
+	}
+}
+
+func TestErase_openHour(t *testing.T) {
+	// given
+	rootDir := t.TempDir()
+	currentDay := day(2023, 9, 21)
+	hour := dayWithHour(currentDay, 3)
+	hourPath := makeHourPath(makeDayPath(rootDir, "www_example_com", currentDay), hour)
+	require.NoError(t, os.MkdirAll(filepath.Dir(hourPath), os.ModePerm))
+	require.NoError(t, os.WriteFile(hourPath, []byte(keptLine+erasedLine), os.ModePerm))
+
+	// when the backup appends to the hour file
+	removed, err := Erase(rootDir, types.ErasureRequest{SessionIDs: []string{"session1"}}, hour.Add(time.Hour))
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 0, removed)
+
+	// when the hour ended
+	removed, err = Erase(rootDir, types.ErasureRequest{SessionIDs: []string{"session1"}}, hour.Add(time.Hour+2*time.Minute))
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	hourContent, err := os.ReadFile(hourPath)
+	require.NoError(t, err)
+	require.Equal(t, keptLine, string(hourContent))
+	require.NoFileExists(t, hourPath+tempSuffix)
+}
+
+func TestErase_pageIDs(t *testing.T) {
+	// given
+	rootDir := t.TempDir()
+	currentDay := day(2023, 9, 21)
+	erasedPage := `{"pid":"page1","rt.si":"session1","u":"https://www.example.com/"}` + "\n"
+	keptPage := `{"pid":"page2","rt.si":"session1","u":"https://www.example.com/about"}` + "\n"
+	hourPath := makeHourPath(makeDayPath(rootDir, "www_example_com", currentDay), dayWithHour(currentDay, 3))
+	require.NoError(t, os.MkdirAll(filepath.Dir(hourPath), os.ModePerm))
+	require.NoError(t, os.WriteFile(hourPath, []byte(erasedPage+keptPage), os.ModePerm))
+
+	// when
+	removed, err := Erase(rootDir, types.ErasureRequest{PageIDs: []string{"page1"}}, currentDay.AddDate(0, 0, 1))
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	hourContent, err := os.ReadFile(hourPath)
+	require.NoError(t, err)
+	require.Equal(t, keptPage, string(hourContent))
+}
+
+func TestErase_missingDirectory(t *testing.T) {
+	removed, err := Erase(filepath.Join(t.TempDir(), "archive"), types.ErasureRequest{SessionIDs: []string{"session1"}}, time.Now())
+
+	require.NoError(t, err)
+	require.Equal(t, 0, removed)
+}
@@ -1,9 +1,13 @@
 package beacon
 
 import (
+	"net/url"
 	"testing"
 
+	"github.com/basicrum/front_basicrum_go/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ua-parser/uap-go/uaparser"
 )
 
 func TestMobDlFloatToInt(t *testing.T) {
@@ -27,3 +31,50 @@ func TestMobDlFloatToInt(t *testing.T) {
 		"",
 		RoundFloatParam("bad val"))
 }
+
+func TestConvertToRumEvent_WebVitals(t *testing.T) {
+	uaP, err := uaparser.New("../assets/uaparser_regexes.yaml")
+	require.NoError(t, err)
+	event := &types.Event{
+		RequestParameters: url.Values{
+			"u":           {"https://www.example.com/"},
+			"pt.lcp":      {"2233"},
+			"pt.lcp.el":   {"IMG"},
+			"pt.lcp.e":    {"div.hero>img"},
+			"pt.lcp.src":  {"https://www.example.com/hero.jpg"},
+			"pt.lcp.s":    {"51200"},
+			"pt.lcp.ttfb": {"412.6"},
+			"pt.lcp.rld":  {"120"},
+			"pt.lcp.rlt":  {"980"},
+			"pt.lcp.erd":  {"720"},
+			"et.inp":      {"184"},
+			"et.inp.e":    {"button#buy"},
+			"et.inp.t":    {"5321.4"},
+			"c.cls":       {"0.12"},
+			"c.cls.d":     {"div.banner"},
+			"c.cls.tm":    {"1890"},
+		},
+	}
+
+	rumEvent := ConvertToRumEvent(FromEvent(event), event, NewUserAgentParser(uaP), nil)
+
+	require.Equal(t, map[string]any{
+		"largest_contentful_paint":  uint16(2233),
+		"lcp_element":               "IMG",
+		"lcp_element_selector":      "div.hero>img",
+		"lcp_resource_url":          "https://www.example.com/hero.jpg",
+		"lcp_size":                  uint32(51200),
+		"lcp_ttfb":                  uint16(413),
+		"lcp_resource_load_delay":   uint16(120),
+		"lcp_resource_load_time":    uint16(980),
+		"lcp_render_delay":          uint16(720),
+		"interaction_to_next_paint": uint16(184),
+		"inp_target":                "button#buy",
+		"inp_time":                  uint32(5321),
+		"cumulative_layout_shift":   float32(0.12),
+		"cls_source":                "div.banner",
+		"cls_time":                  uint32(1890),
+		"redirect_duration":         uint16(0),
+		"redirects_count":           uint8(0),
+	}, rumEvent.Columns)
+}
@@ -0,0 +1,212 @@
+package beacon
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// UserAgentSourceUserAgent the browser, operating system and device are parsed from User-Agent header
+	UserAgentSourceUserAgent = "user_agent"
+	// UserAgentSourceClientHints at least one of the fields is taken from User-Agent Client Hints headers
+	UserAgentSourceClientHints = "client_hints"
+
+	maxVersionParts         = 3
+	windows11PlatformMajor  = 13
+	clientHintMobileEnabled = "?1"
+	clientHintMobileOff     = "?0"
+)
+
+// ClientHintsHeaders are the User-Agent Client Hints headers requested from the browser
+// nolint: gochecknoglobals
+var ClientHintsHeaders = []string{
+	"Sec-CH-UA",
+	"Sec-CH-UA-Mobile",
+	"Sec-CH-UA-Platform",
+	"Sec-CH-UA-Platform-Version",
+	"Sec-CH-UA-Full-Version-List",
+	"Sec-CH-UA-Model",
+}
+
+// brandFamilies maps Client Hints brands to uap-go browser families
+// nolint: gochecknoglobals
+var brandFamilies = map[string]string{
+	"Google Chrome":  "Chrome",
+	"Microsoft Edge": "Edge",
+	"Opera":          "Opera",
+	"Brave":          "Brave",
+	"Chromium":       "Chromium",
+}
+
+// mobileBrandFamilies maps Client Hints brands to uap-go mobile browser families
+// nolint: gochecknoglobals
+var mobileBrandFamilies = map[string]string{
+	"Google Chrome":  "Chrome Mobile",
+	"Microsoft Edge": "Edge Mobile",
+	"Opera":          "Opera Mobile",
+}
+
+// platformFamilies maps Client Hints platforms to uap-go operating system families
+// nolint: gochecknoglobals
+var platformFamilies = map[string]string{
+	"Windows":   "Windows",
+	"macOS":     "Mac OS X",
+	"Android":   "Android",
+	"Chrome OS": "Chrome OS",
+	"Linux":     "Linux",
+	"iOS":       "iOS",
+}
+
+// ClientHintsBrand is brand and version from Sec-CH-UA-Full-Version-List header
+type ClientHintsBrand struct {
+	Brand   string
+	Version string
+}
+
+// ClientHints contains the User-Agent Client Hints request headers
+type ClientHints struct {
+	Brands          []ClientHintsBrand
+	Platform        string
+	PlatformVersion string
+	Model           string
+	Mobile          string
+}
+
+// ClientHintsFromHeaders parses User-Agent Client Hints from request headers
+func ClientHintsFromHeaders(headers http.Header) ClientHints {
+	if headers == nil {
+		return ClientHints{}
+	}
+	return ClientHints{
+		Brands:          parseBrandList(headers.Get("Sec-CH-UA-Full-Version-List")),
+		Platform:        unquote(headers.Get("Sec-CH-UA-Platform")),
+		PlatformVersion: unquote(headers.Get("Sec-CH-UA-Platform-Version")),
+		Model:           unquote(headers.Get("Sec-CH-UA-Model")),
+		Mobile:          strings.TrimSpace(headers.Get("Sec-CH-UA-Mobile")),
+	}
+}
+
+// parseBrandList parses structured header list: "Chromium";v="122.0.6261.94", "Google Chrome";v="122.0.6261.94"
+func parseBrandList(value string) []ClientHintsBrand {
+	var result []ClientHintsBrand
+	for _, item := range strings.Split(value, ",") {
+		params := strings.Split(item, ";")
+		brand := unquote(params[0])
+		if brand == "" {
+			continue
+		}
+		var version string
+		for _, param := range params[1:] {
+			key, paramValue, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && key == "v" {
+				version = unquote(paramValue)
+			}
+		}
+		result = append(result, ClientHintsBrand{Brand: brand, Version: version})
+	}
+	return result
+}
+
+// brand returns the most specific not GREASE brand
+// Chromium is used only when there is no other known brand
+func (c ClientHints) brand() (ClientHintsBrand, bool) {
+	var fallback *ClientHintsBrand
+	for i, item := range c.Brands {
+		if _, known := brandFamilies[item.Brand]; !known {
+			continue
+		}
+		if item.Brand != "Chromium" {
+			return item, true
+		}
+		fallback = &c.Brands[i]
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return ClientHintsBrand{}, false
+}
+
+func (c ClientHints) browserFamily(brand string) string {
+	if c.Mobile == clientHintMobileEnabled {
+		if family, ok := mobileBrandFamilies[brand]; ok {
+			return family
+		}
+	}
+	return brandFamilies[brand]
+}
+
+func (c ClientHints) operatingSystemVersion() string {
+	if c.PlatformVersion == "" {
+		return ""
+	}
+	if c.Platform != "Windows" {
+		return trimVersion(c.PlatformVersion)
+	}
+	// https://learn.microsoft.com/en-us/microsoft-edge/web-platform/how-to-detect-win11
+	major, err := strconv.Atoi(strings.Split(c.PlatformVersion, ".")[0])
+	if err != nil {
+		return ""
+	}
+	if major >= windows11PlatformMajor {
+		return "11"
+	}
+	if major > 0 {
+		return "10"
+	}
+	return ""
+}
+
+// userAgentFields are the rum event fields resolved from the user agent and client hints
+type userAgentFields struct {
+	browserName            string
+	browserVersion         string
+	operatingSystem        string
+	operatingSystemVersion string
+	source                 string
+}
+
+// resolveUserAgent prefers the client hints and falls back to the parsed user agent
+// The device fields are resolved by DeviceClassifier
+func resolveUserAgent(userAgent *UserAgent, hints ClientHints) userAgentFields {
+	result := userAgentFields{
+		browserName:            userAgent.Client.UserAgent.Family,
+		browserVersion:         userAgent.Client.UserAgent.ToVersionString(),
+		operatingSystem:        userAgent.Client.Os.Family,
+		operatingSystemVersion: userAgent.Client.Os.ToVersionString(),
+		source:                 UserAgentSourceUserAgent,
+	}
+
+	if brand, ok := hints.brand(); ok {
+		result.browserName = hints.browserFamily(brand.Brand)
+		if brand.Version != "" {
+			result.browserVersion = trimVersion(brand.Version)
+		}
+		result.source = UserAgentSourceClientHints
+	}
+	if family, ok := platformFamilies[hints.Platform]; ok {
+		result.operatingSystem = family
+		result.source = UserAgentSourceClientHints
+	}
+	if version := hints.operatingSystemVersion(); version != "" {
+		result.operatingSystemVersion = version
+		result.source = UserAgentSourceClientHints
+	}
+	return result
+}
+
+// trimVersion keeps major.minor.patch and removes the trailing zero parts: 14.0.0 -> 14, 122.0.6261.94 -> 122.0.6261
+func trimVersion(version string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) > maxVersionParts {
+		parts = parts[:maxVersionParts]
+	}
+	for len(parts) > 1 && parts[len(parts)-1] == "0" {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ".")
+}
+
+func unquote(value string) string {
+	return strings.Trim(strings.TrimSpace(value), "\"")
+}
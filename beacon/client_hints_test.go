@@ -0,0 +1,135 @@
+package beacon
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+func TestClientHintsFromHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Sec-CH-UA-Full-Version-List", `"Chromium";v="122.0.6261.94", "Not(A:Brand";v="24.0.0.0", "Google Chrome";v="122.0.6261.94"`)
+	headers.Set("Sec-CH-UA-Platform", `"Windows"`)
+	headers.Set("Sec-CH-UA-Platform-Version", `"15.0.0"`)
+	headers.Set("Sec-CH-UA-Model", `""`)
+	headers.Set("Sec-CH-UA-Mobile", "?0")
+
+	require.Equal(t, ClientHints{
+		Brands: []ClientHintsBrand{
+			{Brand: "Chromium", Version: "122.0.6261.94"},
+			{Brand: "Not(A:Brand", Version: "24.0.0.0"},
+			{Brand: "Google Chrome", Version: "122.0.6261.94"},
+		},
+		Platform:        "Windows",
+		PlatformVersion: "15.0.0",
+		Mobile:          "?0",
+	}, ClientHintsFromHeaders(headers))
+
+	require.Equal(t, ClientHints{}, ClientHintsFromHeaders(nil))
+}
+
+func TestResolveUserAgent(t *testing.T) {
+	parsed := &UserAgent{
+		Client: &uaparser.Client{
+			UserAgent: &uaparser.UserAgent{Family: "Chrome Mobile", Major: "122", Minor: "0", Patch: "0"},
+			Os:        &uaparser.Os{Family: "Android", Major: "10"},
+			Device:    &uaparser.Device{Family: "K", Brand: "Generic", Model: "K"},
+		},
+	}
+	tests := []struct {
+		name  string
+		hints ClientHints
+		want  userAgentFields
+	}{
+		{
+			name: "without client hints",
+			want: userAgentFields{
+				browserName:            "Chrome Mobile",
+				browserVersion:         "122.0.0",
+				operatingSystem:        "Android",
+				operatingSystemVersion: "10",
+				source:                 UserAgentSourceUserAgent,
+			},
+		},
+		{
+			name: "mobile client hints",
+			hints: ClientHints{
+				Brands: []ClientHintsBrand{
+					{Brand: "Not(A:Brand", Version: "24.0.0.0"},
+					{Brand: "Chromium", Version: "122.0.6261.94"},
+					{Brand: "Google Chrome", Version: "122.0.6261.94"},
+				},
+				Platform:        "Android",
+				PlatformVersion: "14.0.0",
+				Model:           "Pixel 7",
+				Mobile:          "?1",
+			},
+			want: userAgentFields{
+				browserName:            "Chrome Mobile",
+				browserVersion:         "122.0.6261",
+				operatingSystem:        "Android",
+				operatingSystemVersion: "14",
+				source:                 UserAgentSourceClientHints,
+			},
+		},
+		{
+			name: "android tablet client hints",
+			hints: ClientHints{
+				Brands: []ClientHintsBrand{
+					{Brand: "Chromium", Version: "122.0.6261.94"},
+				},
+				Platform: "Android",
+				Mobile:   "?0",
+			},
+			want: userAgentFields{
+				browserName:            "Chromium",
+				browserVersion:         "122.0.6261",
+				operatingSystem:        "Android",
+				operatingSystemVersion: "10",
+				source:                 UserAgentSourceClientHints,
+			},
+		},
+		{
+			name: "windows 11 client hints",
+			hints: ClientHints{
+				Brands: []ClientHintsBrand{
+					{Brand: "Microsoft Edge", Version: "121.0.2277.128"},
+				},
+				Platform:        "Windows",
+				PlatformVersion: "15.0.0",
+			},
+			want: userAgentFields{
+				browserName:            "Edge",
+				browserVersion:         "121.0.2277",
+				operatingSystem:        "Windows",
+				operatingSystemVersion: "11",
+				source:                 UserAgentSourceClientHints,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, resolveUserAgent(parsed, tt.hints))
+		})
+	}
+}
+
+func Test_trimVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "122.0.6261.94", want: "122.0.6261"},
+		{version: "14.0.0", want: "14"},
+		{version: "10.15.7", want: "10.15.7"},
+		{version: "17.2.0", want: "17.2"},
+		{version: "0.0.0", want: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			require.Equal(t, tt.want, trimVersion(tt.version))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package beacon
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+const (
+	// CustomMetricPrefix is the prefix of Boomerang custom metrics parameters
+	CustomMetricPrefix = "cmet."
+	// CustomDimensionPrefix is the prefix of Boomerang custom dimensions parameters
+	CustomDimensionPrefix = "cdim."
+	// CustomTimerPrefix is the prefix of Boomerang custom timers parameters
+	CustomTimerPrefix = "ctim."
+
+	allowlistAnyHostname = "*"
+	allowlistAnyName     = "*"
+
+	// maxTrackedHostnames limits the hostnames with counted names, the least recently used hostname is evicted
+	maxTrackedHostnames = 10000
+)
+
+// Metrics contains the numeric values by name
+type Metrics map[string]float64
+
+// Dimensions contains the string values by name
+type Dimensions map[string]string
+
+// customParameters returns the request parameters with prefix by name without the prefix
+func customParameters(values url.Values, prefix string) map[string]string {
+	var result map[string]string
+	for key := range values {
+		name, found := strings.CutPrefix(key, prefix)
+		if !found || name == "" {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[name] = values.Get(key)
+	}
+	return result
+}
+
+// parseMetrics parses the numeric custom parameters. The invalid numbers are skipped
+func parseMetrics(values map[string]string) Metrics {
+	result := Metrics{}
+	for name, value := range values {
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		result[name] = v
+	}
+	return result
+}
+
+func parseDimensions(values map[string]string) Dimensions {
+	result := Dimensions{}
+	for name, value := range values {
+		result[name] = value
+	}
+	return result
+}
+
+// CustomDataAllowlist contains the allowed custom parameters by hostname
+// The parameter names contain the prefix: cdim.ab_variant, cmet.revenue, ctim.hero_image
+type CustomDataAllowlist map[string]map[string]bool
+
+// ParseCustomDataAllowlist parses allowlist "www.example.com=cdim.ab_variant|cmet.revenue;*=cdim.release"
+// The hostname "*" is used for the hostnames without entry. The name "*" allows all the parameters
+func ParseCustomDataAllowlist(value string) (CustomDataAllowlist, error) {
+	result := CustomDataAllowlist{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		hostname, names, found := strings.Cut(entry, "=")
+		hostname = strings.TrimSpace(hostname)
+		if !found || hostname == "" {
+			return nil, fmt.Errorf("invalid custom data allowlist entry[%v]", entry)
+		}
+		allowed := map[string]bool{}
+		for _, name := range strings.Split(names, "|") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name != allowlistAnyName && !hasCustomDataPrefix(name) {
+				return nil, fmt.Errorf("invalid custom data allowlist name[%v] hostname[%v]", name, hostname)
+			}
+			allowed[name] = true
+		}
+		result[hostname] = allowed
+	}
+	return result, nil
+}
+
+func hasCustomDataPrefix(name string) bool {
+	return strings.HasPrefix(name, CustomMetricPrefix) ||
+		strings.HasPrefix(name, CustomDimensionPrefix) ||
+		strings.HasPrefix(name, CustomTimerPrefix)
+}
+
+// CustomDataFilter keeps only the allowed custom metrics, dimensions and timers
+// The names listed in the allowlist are always kept. The number of distinct names allowed by the name "*"
+// or the empty allowlist is capped per hostname to protect the map columns from unbounded cardinality
+// The cap is counted in memory per process and per cache lifetime, the counted names are not loaded from the stored rows.
+// So the restarted process, every server instance and the hostname evicted from the cache count the names again from zero
+type CustomDataFilter struct {
+	allowlist      CustomDataAllowlist
+	maxNames       int
+	maxValueLength int
+
+	lock sync.Mutex
+	// names contains the counted names by hostname, it is bounded because the hostnames come from the beacons
+	names *simplelru.LRU
+}
+
+// NewCustomDataFilter creates custom data filter
+// Empty allowlist allows all the names. Zero maxNames or maxValueLength means unlimited
+func NewCustomDataFilter(allowlist CustomDataAllowlist, maxNames, maxValueLength int) *CustomDataFilter {
+	// the error is returned only for the non positive size
+	names, _ := simplelru.NewLRU(maxTrackedHostnames, nil)
+	return &CustomDataFilter{
+		allowlist:      allowlist,
+		maxNames:       maxNames,
+		maxValueLength: maxValueLength,
+		names:          names,
+	}
+}
+
+// Filter removes the not allowed custom data from the rum event
+func (f *CustomDataFilter) Filter(rumEvent *RumEvent) {
+	for name := range rumEvent.Custom_Metrics {
+		if !f.allowed(rumEvent.Hostname, CustomMetricPrefix+name) {
+			delete(rumEvent.Custom_Metrics, name)
+		}
+	}
+	for name := range rumEvent.Custom_Timers {
+		if !f.allowed(rumEvent.Hostname, CustomTimerPrefix+name) {
+			delete(rumEvent.Custom_Timers, name)
+		}
+	}
+	for name, value := range rumEvent.Custom_Dimensions {
+		if !f.allowed(rumEvent.Hostname, CustomDimensionPrefix+name) {
+			delete(rumEvent.Custom_Dimensions, name)
+			continue
+		}
+		if f.maxValueLength > 0 && len(value) > f.maxValueLength {
+			rumEvent.Custom_Dimensions[name] = strings.ToValidUTF8(value[:f.maxValueLength], "")
+		}
+	}
+}
+
+func (f *CustomDataFilter) allowed(hostname, name string) bool {
+	allowed, listed := f.allowlist.allowed(hostname, name)
+	if !allowed {
+		return false
+	}
+	if listed || f.maxNames <= 0 {
+		return true
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var names map[string]bool
+	if value, ok := f.names.Get(hostname); ok {
+		names = value.(map[string]bool)
+	} else {
+		names = map[string]bool{}
+		f.names.Add(hostname, names)
+	}
+	if names[name] {
+		return true
+	}
+	if len(names) >= f.maxNames {
+		return false
+	}
+	names[name] = true
+	return true
+}
+
+// allowed checks the name of the hostname, listed is true when the name is explicitly listed in the allowlist
+func (a CustomDataAllowlist) allowed(hostname, name string) (allowed, listed bool) {
+	if len(a) == 0 {
+		return true, false
+	}
+	names, ok := a[hostname]
+	if !ok {
+		names, ok = a[allowlistAnyHostname]
+	}
+	if !ok {
+		return false, false
+	}
+	if names[name] {
+		return true, true
+	}
+	return names[allowlistAnyName], false
+}
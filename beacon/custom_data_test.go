@@ -0,0 +1,164 @@
+package beacon
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomParameters(t *testing.T) {
+	values := url.Values{
+		"cmet.revenue":    {"12.5"},
+		"cmet.items":      {"abc"},
+		"cdim.ab_variant": {"B"},
+		"ctim.hero":       {"1234"},
+		"cmet.":           {"1"},
+		"u":               {"https://www.example.com"},
+	}
+
+	require.Equal(t, Metrics{"revenue": 12.5}, parseMetrics(customParameters(values, CustomMetricPrefix)))
+	require.Equal(t, Dimensions{"ab_variant": "B"}, parseDimensions(customParameters(values, CustomDimensionPrefix)))
+	require.Equal(t, Metrics{"hero": 1234}, parseMetrics(customParameters(values, CustomTimerPrefix)))
+	require.Equal(t, Dimensions{}, parseDimensions(customParameters(url.Values{}, CustomDimensionPrefix)))
+}
+
+func TestParseCustomDataAllowlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    CustomDataAllowlist
+		wantErr bool
+	}{
+		{
+			name:  "hostnames",
+			value: "www.example.com=cdim.ab_variant|cmet.revenue; *=cdim.release",
+			want: CustomDataAllowlist{
+				"www.example.com": {"cdim.ab_variant": true, "cmet.revenue": true},
+				"*":               {"cdim.release": true},
+			},
+		},
+		{
+			name:  "all names",
+			value: "www.example.com=*",
+			want: CustomDataAllowlist{
+				"www.example.com": {"*": true},
+			},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  CustomDataAllowlist{},
+		},
+		{
+			name:    "missing hostname",
+			value:   "=cdim.ab_variant",
+			wantErr: true,
+		},
+		{
+			name:    "missing prefix",
+			value:   "www.example.com=ab_variant",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCustomDataAllowlist(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCustomDataFilter_Filter(t *testing.T) {
+	newRumEvent := func(hostname string) *RumEvent {
+		return &RumEvent{
+			Hostname:          hostname,
+			Custom_Metrics:    Metrics{"revenue": 12.5, "items": 3},
+			Custom_Dimensions: Dimensions{"ab_variant": "B", "release": strings.Repeat("x", 10)},
+			Custom_Timers:     Metrics{"hero": 1234},
+		}
+	}
+	allowlist := CustomDataAllowlist{
+		"www.example.com": {"cmet.revenue": true, "cdim.ab_variant": true, "cdim.release": true},
+		"*":               {"ctim.hero": true},
+	}
+
+	t.Run("allowlist", func(t *testing.T) {
+		f := NewCustomDataFilter(allowlist, 0, 0)
+
+		rumEvent := newRumEvent("www.example.com")
+		f.Filter(rumEvent)
+		require.Equal(t, Metrics{"revenue": 12.5}, rumEvent.Custom_Metrics)
+		require.Equal(t, Dimensions{"ab_variant": "B", "release": "xxxxxxxxxx"}, rumEvent.Custom_Dimensions)
+		require.Equal(t, Metrics{}, rumEvent.Custom_Timers)
+
+		rumEvent = newRumEvent("other.example.com")
+		f.Filter(rumEvent)
+		require.Equal(t, Metrics{}, rumEvent.Custom_Metrics)
+		require.Equal(t, Dimensions{}, rumEvent.Custom_Dimensions)
+		require.Equal(t, Metrics{"hero": 1234.0}, rumEvent.Custom_Timers)
+	})
+
+	t.Run("empty allowlist allows all", func(t *testing.T) {
+		rumEvent := newRumEvent("www.example.com")
+		NewCustomDataFilter(nil, 0, 4).Filter(rumEvent)
+		require.Equal(t, Metrics{"revenue": 12.5, "items": 3}, rumEvent.Custom_Metrics)
+		require.Equal(t, Dimensions{"ab_variant": "B", "release": "xxxx"}, rumEvent.Custom_Dimensions)
+		require.Equal(t, Metrics{"hero": 1234.0}, rumEvent.Custom_Timers)
+	})
+
+	t.Run("max names per hostname", func(t *testing.T) {
+		f := NewCustomDataFilter(nil, 2, 0)
+
+		rumEvent := &RumEvent{Hostname: "www.example.com", Custom_Metrics: Metrics{"revenue": 1}}
+		f.Filter(rumEvent)
+		require.Equal(t, Metrics{"revenue": 1.0}, rumEvent.Custom_Metrics)
+
+		rumEvent = &RumEvent{Hostname: "www.example.com", Custom_Dimensions: Dimensions{"ab_variant": "A"}}
+		f.Filter(rumEvent)
+		require.Equal(t, Dimensions{"ab_variant": "A"}, rumEvent.Custom_Dimensions)
+
+		// the third distinct name is dropped, the known names are kept
+		rumEvent = &RumEvent{
+			Hostname:          "www.example.com",
+			Custom_Metrics:    Metrics{"revenue": 2},
+			Custom_Dimensions: Dimensions{"ab_variant": "B", "user_id": "123"},
+		}
+		f.Filter(rumEvent)
+		require.Equal(t, Metrics{"revenue": 2.0}, rumEvent.Custom_Metrics)
+		require.Equal(t, Dimensions{"ab_variant": "B"}, rumEvent.Custom_Dimensions)
+
+		// the limit is per hostname
+		rumEvent = &RumEvent{Hostname: "shop.example.com", Custom_Dimensions: Dimensions{"user_id": "123"}}
+		f.Filter(rumEvent)
+		require.Equal(t, Dimensions{"user_id": "123"}, rumEvent.Custom_Dimensions)
+	})
+
+	t.Run("max names skips listed names", func(t *testing.T) {
+		f := NewCustomDataFilter(CustomDataAllowlist{"*": {"*": true, "cdim.release": true}}, 1, 0)
+
+		rumEvent := &RumEvent{Hostname: "www.example.com", Custom_Dimensions: Dimensions{"user_id": "123"}}
+		f.Filter(rumEvent)
+		require.Equal(t, Dimensions{"user_id": "123"}, rumEvent.Custom_Dimensions)
+
+		// the listed name is kept after the limit, the other names are dropped
+		rumEvent = &RumEvent{Hostname: "www.example.com", Custom_Dimensions: Dimensions{"release": "1.0", "ab_variant": "A"}}
+		f.Filter(rumEvent)
+		require.Equal(t, Dimensions{"release": "1.0"}, rumEvent.Custom_Dimensions)
+	})
+
+	t.Run("tracked hostnames are bounded", func(t *testing.T) {
+		f := NewCustomDataFilter(nil, 1, 0)
+		for i := 0; i <= maxTrackedHostnames; i++ {
+			f.Filter(&RumEvent{Hostname: fmt.Sprintf("host%d.example.com", i), Custom_Metrics: Metrics{"revenue": 1}})
+		}
+		require.Equal(t, maxTrackedHostnames, f.names.Len())
+	})
+}
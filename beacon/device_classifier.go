@@ -0,0 +1,245 @@
+package beacon
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+const (
+	// DeviceTypeMobile phones
+	DeviceTypeMobile = "mobile"
+	// DeviceTypeTablet tablets including iPad in desktop mode
+	DeviceTypeTablet = "tablet"
+	// DeviceTypeDesktop desktop and laptop computers
+	DeviceTypeDesktop = "desktop"
+	// DeviceTypeTV smart TVs and streaming devices
+	DeviceTypeTV = "tv"
+	// DeviceTypeConsole game consoles
+	DeviceTypeConsole = "console"
+	// DeviceTypeBot crawlers and automated browsers
+	DeviceTypeBot = "bot"
+	// DeviceTypeUnknown not enough information to classify the device
+	DeviceTypeUnknown = "unknown"
+
+	// maxMobileScreenShortSide the short side in CSS pixels below which the screen is a phone screen
+	maxMobileScreenShortSide = 600
+	// maxTabletScreenShortSide the short side in CSS pixels up to which touch screen is a tablet screen
+	maxTabletScreenShortSide = 1024
+
+	// reducedUserAgentModel is the model placeholder of the reduced Android User-Agent string
+	reducedUserAgentModel = "K"
+	spiderDeviceFamily    = "Spider"
+
+	// genericAndroid and genericAndroidTablet are the uap-go brands of Android devices without known manufacturer
+	genericAndroid       = "Generic_Android"
+	genericAndroidTablet = "Generic_Android_Tablet"
+
+	// macModel is the uap-go model of Macintosh User-Agent which is sent by iPadOS Safari in desktop mode
+	macModel  = "Mac"
+	iPadModel = "iPad"
+)
+
+// nolint: gochecknoglobals
+var defaultDeviceClassifier = NewDeviceClassifier()
+
+// nolint: gochecknoglobals
+var desktopOperatingSystems = map[string]bool{
+	"Windows":   true,
+	"Mac OS X":  true,
+	"Linux":     true,
+	"Chrome OS": true,
+	"Ubuntu":    true,
+	"Fedora":    true,
+	"Debian":    true,
+	"FreeBSD":   true,
+	"OpenBSD":   true,
+}
+
+// nolint: gochecknoglobals
+var desktopPlatforms = map[string]bool{
+	"Win32":        true,
+	"Win64":        true,
+	"MacIntel":     true,
+	"Linux x86_64": true,
+	"Linux i686":   true,
+}
+
+// DeviceSignals are the inputs of the device classification
+type DeviceSignals struct {
+	// UserAgent is the User-Agent header
+	UserAgent string
+	// Client is the uap-go parsed User-Agent header
+	Client *uaparser.Client
+	// Hints are the User-Agent Client Hints headers
+	Hints ClientHints
+	// Platform is navigator.platform sent by Boomerang as ua.plt
+	Platform string
+	// ScreenXY is the screen size sent by Boomerang as scr.xy
+	ScreenXY string
+	// MaxTouchPoints is navigator.maxTouchPoints sent by Boomerang as scr.mtp
+	MaxTouchPoints string
+}
+
+// Device is the classified device
+type Device struct {
+	Type         string
+	Manufacturer string
+	Model        string
+	// ClientHints is true when the type or the model is taken from User-Agent Client Hints
+	ClientHints bool
+}
+
+// DeviceClassifier classifies the device type consistently from all the available signals
+type DeviceClassifier struct {
+	botPattern     *regexp.Regexp
+	tvPattern      *regexp.Regexp
+	consolePattern *regexp.Regexp
+	tabletPattern  *regexp.Regexp
+	mobilePattern  *regexp.Regexp
+}
+
+// NewDeviceClassifier creates device classifier
+func NewDeviceClassifier() *DeviceClassifier {
+	return &DeviceClassifier{
+		botPattern:     regexp.MustCompile(`(?i)(headlesschrome|lighthouse|phantomjs|^curl/|^wget/|python-requests|go-http-client)`),
+		tvPattern:      regexp.MustCompile(`(?i)(smart-?tv|hbbtv|web0s|webos\.tv|googletv|android tv|appletv|crkey|bravia|roku|\bAFT[A-Z]|\bTV Safari)`),
+		consolePattern: regexp.MustCompile(`(?i)(playstation|xbox|nintendo)`),
+		tabletPattern:  regexp.MustCompile(`(?i)(ipad|tablet|kindle|silk/|playbook)`),
+		mobilePattern:  regexp.MustCompile(`(?i)(mobi|iphone|ipod|windows phone|blackberry|opera mini)`),
+	}
+}
+
+// ClassifyDevice classifies the device with the default classifier
+func ClassifyDevice(signals DeviceSignals) Device {
+	return defaultDeviceClassifier.Classify(signals)
+}
+
+// Classify returns device type (mobile/tablet/desktop/tv/console/bot/unknown), manufacturer and model
+func (c *DeviceClassifier) Classify(signals DeviceSignals) Device {
+	result := Device{}
+	if signals.Client != nil && signals.Client.Device != nil {
+		result.Manufacturer = signals.Client.Device.Brand
+		result.Model = deviceModel(signals.Client.Device)
+	}
+	if signals.Hints.Model != "" {
+		result.Model = signals.Hints.Model
+		result.ClientHints = true
+	}
+	deviceType, fromHints := c.deviceType(signals)
+	result.Type = deviceType
+	result.Manufacturer = genericManufacturer(result.Manufacturer, deviceType)
+	if deviceType == DeviceTypeTablet && result.Model == macModel {
+		// the touch screen Mac is iPad in desktop mode
+		result.Model = iPadModel
+	}
+	result.ClientHints = result.ClientHints || fromHints
+	return result
+}
+
+// genericManufacturer replaces the generic Android brand which contradicts the device type, for example
+// the reduced User-Agent without "Mobile" token is parsed as tablet while the client hints report mobile
+func genericManufacturer(manufacturer, deviceType string) string {
+	switch {
+	case manufacturer == genericAndroidTablet && deviceType == DeviceTypeMobile:
+		return genericAndroid
+	case manufacturer == genericAndroid && deviceType == DeviceTypeTablet:
+		return genericAndroidTablet
+	}
+	return manufacturer
+}
+
+// nolint: cyclop
+func (c *DeviceClassifier) deviceType(signals DeviceSignals) (string, bool) {
+	userAgent := signals.UserAgent
+	hints := signals.Hints
+	if userAgent == "" && hints.Mobile == "" {
+		return DeviceTypeUnknown, false
+	}
+	deviceFamily, osFamily := clientFamilies(signals.Client)
+
+	switch {
+	case deviceFamily == spiderDeviceFamily || c.botPattern.MatchString(userAgent):
+		return DeviceTypeBot, false
+	case c.tvPattern.MatchString(userAgent) || strings.Contains(deviceFamily, "TV"):
+		return DeviceTypeTV, false
+	case c.consolePattern.MatchString(userAgent):
+		return DeviceTypeConsole, false
+	case hints.Mobile == clientHintMobileEnabled:
+		return DeviceTypeMobile, true
+	case hints.Mobile == clientHintMobileOff && hints.Platform == "Android":
+		return DeviceTypeTablet, true
+	case c.isTablet(signals, deviceFamily, osFamily):
+		return DeviceTypeTablet, false
+	case c.mobilePattern.MatchString(userAgent) || signals.Platform == "iPhone":
+		return DeviceTypeMobile, false
+	case desktopOperatingSystems[osFamily] || desktopPlatforms[signals.Platform]:
+		return DeviceTypeDesktop, false
+	case hints.Mobile == clientHintMobileOff:
+		return DeviceTypeDesktop, true
+	}
+	return screenDeviceType(signals), false
+}
+
+func (c *DeviceClassifier) isTablet(signals DeviceSignals, deviceFamily, osFamily string) bool {
+	if c.tabletPattern.MatchString(signals.UserAgent) || c.tabletPattern.MatchString(deviceFamily) {
+		return true
+	}
+	if signals.Client != nil && signals.Client.Device != nil && strings.HasSuffix(signals.Client.Device.Brand, "_Tablet") {
+		return true
+	}
+	// Android tablets do not contain "Mobile" token in User-Agent
+	if osFamily == "Android" && !c.mobilePattern.MatchString(signals.UserAgent) {
+		return true
+	}
+	// iPadOS Safari requests desktop website by default and reports MacIntel platform
+	return (signals.Platform == "MacIntel" || osFamily == "Mac OS X") && parseInt(signals.MaxTouchPoints) > 1
+}
+
+// screenDeviceType classifies the device only by the screen size and touch support
+func screenDeviceType(signals DeviceSignals) string {
+	width, height := getScreenSize(signals.ScreenXY)
+	shortSide := min(parseInt(width), parseInt(height))
+	if shortSide <= 0 {
+		return DeviceTypeUnknown
+	}
+	if shortSide < maxMobileScreenShortSide {
+		return DeviceTypeMobile
+	}
+	if parseInt(signals.MaxTouchPoints) > 0 && shortSide <= maxTabletScreenShortSide {
+		return DeviceTypeTablet
+	}
+	return DeviceTypeDesktop
+}
+
+func clientFamilies(client *uaparser.Client) (string, string) {
+	var deviceFamily, osFamily string
+	if client == nil {
+		return deviceFamily, osFamily
+	}
+	if client.Device != nil {
+		deviceFamily = client.Device.Family
+	}
+	if client.Os != nil {
+		osFamily = client.Os.Family
+	}
+	return deviceFamily, osFamily
+}
+
+// deviceModel returns the uap-go model without the placeholders
+func deviceModel(device *uaparser.Device) string {
+	if device.Family == spiderDeviceFamily || device.Model == reducedUserAgentModel {
+		return ""
+	}
+	return device.Model
+}
+
+func parseInt(value string) int {
+	result, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+	return result
+}
@@ -0,0 +1,177 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+func TestDeviceClassifier_Classify(t *testing.T) {
+	uaP, err := uaparser.New("../assets/uaparser_regexes.yaml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		userAgent string
+		hints     ClientHints
+		platform  string
+		screenXY  string
+		touch     string
+		want      Device
+	}{
+		{
+			name:      "windows chrome",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:      Device{Type: DeviceTypeDesktop},
+		},
+		{
+			name:      "mac safari",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+			platform:  "MacIntel",
+			touch:     "0",
+			want:      Device{Type: DeviceTypeDesktop, Manufacturer: "Apple", Model: "Mac"},
+		},
+		{
+			name:      "ipad in desktop mode",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+			platform:  "MacIntel",
+			touch:     "5",
+			want:      Device{Type: DeviceTypeTablet, Manufacturer: "Apple", Model: "iPad"},
+		},
+		{
+			name:      "linux firefox",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			want:      Device{Type: DeviceTypeDesktop},
+		},
+		{
+			name:      "chromebook",
+			userAgent: "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:      Device{Type: DeviceTypeDesktop},
+		},
+		{
+			name:      "iphone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			want:      Device{Type: DeviceTypeMobile, Manufacturer: "Apple", Model: "iPhone"},
+		},
+		{
+			name:      "ipad",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+			want:      Device{Type: DeviceTypeTablet, Manufacturer: "Apple", Model: "iPad"},
+		},
+		{
+			name:      "android reduced user agent",
+			userAgent: "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			want:      Device{Type: DeviceTypeMobile, Manufacturer: "Generic_Android"},
+		},
+		{
+			name:      "android reduced user agent with client hints",
+			userAgent: "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			hints:     ClientHints{Platform: "Android", Model: "Pixel 7", Mobile: "?1"},
+			want:      Device{Type: DeviceTypeMobile, Manufacturer: "Generic_Android", Model: "Pixel 7", ClientHints: true},
+		},
+		{
+			name:      "android tablet by client hints",
+			userAgent: "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			hints:     ClientHints{Platform: "Android", Mobile: "?0"},
+			want:      Device{Type: DeviceTypeTablet, Manufacturer: "Generic_Android_Tablet", ClientHints: true},
+		},
+		{
+			name:      "samsung phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			want:      Device{Type: DeviceTypeMobile, Manufacturer: "Samsung", Model: "SM-S918B"},
+		},
+		{
+			name:      "samsung tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:      Device{Type: DeviceTypeTablet, Manufacturer: "Generic_Android_Tablet", Model: "SM-X700"},
+		},
+		{
+			name:      "kindle fire",
+			userAgent: "Mozilla/5.0 (Linux; Android 9; KFMAWI) AppleWebKit/537.36 (KHTML, like Gecko) Silk/120.3.1 like Chrome/120.0.0.0 Safari/537.36",
+			want:      Device{Type: DeviceTypeTablet, Manufacturer: "Amazon", Model: "Kindle"},
+		},
+		{
+			name:      "windows phone",
+			userAgent: "Mozilla/5.0 (Windows Phone 10.0; Android 6.0.1; Microsoft; Lumia 950) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/52.0.2743.116 Mobile Safari/537.36 Edge/15.15063",
+			want:      Device{Type: DeviceTypeMobile, Manufacturer: "Nokia", Model: "Lumia 950"},
+		},
+		{
+			name:      "googlebot",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      Device{Type: DeviceTypeBot, Manufacturer: "Spider"},
+		},
+		{
+			name:      "googlebot smartphone",
+			userAgent: "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      Device{Type: DeviceTypeBot, Manufacturer: "Spider"},
+		},
+		{
+			name:      "headless chrome",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36",
+			want:      Device{Type: DeviceTypeBot},
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.4.0",
+			want:      Device{Type: DeviceTypeBot},
+		},
+		{
+			name:      "samsung smart tv",
+			userAgent: "Mozilla/5.0 (SMART-TV; LINUX; Tizen 6.0) AppleWebKit/537.36 (KHTML, like Gecko) 76.0.3809.146/6.0 TV Safari/537.36",
+			want:      Device{Type: DeviceTypeTV, Manufacturer: "Samsung", Model: "SMART-TV"},
+		},
+		{
+			name:      "fire tv",
+			userAgent: "Mozilla/5.0 (Linux; Android 9; AFTMM Build/PS7233) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			want:      Device{Type: DeviceTypeTV, Manufacturer: "Generic_Android", Model: "AFTMM"},
+		},
+		{
+			name:      "playstation",
+			userAgent: "Mozilla/5.0 (PlayStation; PlayStation 5/2.26) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0 Safari/605.1.15",
+			want:      Device{Type: DeviceTypeConsole, Manufacturer: "Sony", Model: "PlayStation 5"},
+		},
+		{
+			name:      "xbox",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; Xbox; Xbox One) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edge/44.18363.8131",
+			want:      Device{Type: DeviceTypeConsole},
+		},
+		{
+			name:      "unrecognized user agent falls back to screen size",
+			userAgent: "CustomBrowser/1.0",
+			screenXY:  "390x844",
+			want:      Device{Type: DeviceTypeMobile},
+		},
+		{
+			name:      "unrecognized user agent with touch tablet screen",
+			userAgent: "CustomBrowser/1.0",
+			screenXY:  "1024x768",
+			touch:     "5",
+			want:      Device{Type: DeviceTypeTablet},
+		},
+		{
+			name:      "unrecognized user agent without screen",
+			userAgent: "CustomBrowser/1.0",
+			want:      Device{Type: DeviceTypeUnknown},
+		},
+		{
+			name:     "empty user agent",
+			screenXY: "1536x864",
+			want:     Device{Type: DeviceTypeUnknown},
+		},
+	}
+	classifier := NewDeviceClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifier.Classify(DeviceSignals{
+				UserAgent:      tt.userAgent,
+				Client:         uaP.Parse(tt.userAgent),
+				Hints:          tt.hints,
+				Platform:       tt.platform,
+				ScreenXY:       tt.screenXY,
+				MaxTouchPoints: tt.touch,
+			})
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
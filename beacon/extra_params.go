@@ -0,0 +1,88 @@
+package beacon
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const denylistPrefixSuffix = "*"
+
+// Params contains the request parameter values by name
+type Params map[string]string
+
+// ExtraParamsCollector collects the beacon parameters which are not mapped into columns
+// It allows analysing the data from new Boomerang plugins and BOOMR.addVar before mapping them
+type ExtraParamsCollector struct {
+	known    map[string]bool
+	denylist []string
+	maxSize  int
+}
+
+// NewExtraParamsCollector creates extra parameters collector
+// The denylist contains parameter names, the names ending with "*" are prefixes: "h.cr,dom.res.*"
+// The maxSize limits the total length of the collected names and values. Zero means unlimited
+func NewExtraParamsCollector(registry *Registry, denylist string, maxSize int) *ExtraParamsCollector {
+	known := map[string]bool{}
+	for _, m := range registry.Mappings() {
+		for _, name := range m.Parameters {
+			known[name] = true
+		}
+	}
+	for _, name := range beaconParameters() {
+		known[name] = true
+	}
+	var names []string
+	for _, name := range strings.Split(denylist, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return &ExtraParamsCollector{
+		known:    known,
+		denylist: names,
+		maxSize:  maxSize,
+	}
+}
+
+// Collect returns the not mapped and not denied parameters. The nil collector is disabled and returns nil
+// The parameters are added by name in alphabetical order until the size limit is reached
+func (c *ExtraParamsCollector) Collect(values url.Values) Params {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if c.collected(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := Params{}
+	size := 0
+	for _, name := range names {
+		value := values.Get(name)
+		if c.maxSize > 0 && size+len(name)+len(value) > c.maxSize {
+			continue
+		}
+		size += len(name) + len(value)
+		result[name] = value
+	}
+	return result
+}
+
+func (c *ExtraParamsCollector) collected(name string) bool {
+	if name == "" || c.known[name] || hasCustomDataPrefix(name) {
+		return false
+	}
+	for _, denied := range c.denylist {
+		if prefix, found := strings.CutSuffix(denied, denylistPrefixSuffix); found && strings.HasPrefix(name, prefix) {
+			return false
+		}
+		if name == denied {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package beacon
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraParamsCollector_Collect(t *testing.T) {
+	values := url.Values{
+		"u":             {"https://www.example.com/"},
+		"nt_con_st":     {"1661579573355"},
+		"cdim.variant":  {"A"},
+		"h.cr":          {"crumb"},
+		"dom.res.slow":  {"https://www.example.com/image.jpg"},
+		"ab.test":       {"checkout"},
+		"spa.missed":    {"1"},
+		"plugin.latest": {"12345"},
+	}
+	tests := []struct {
+		name      string
+		collector *ExtraParamsCollector
+		want      Params
+	}{
+		{
+			name:      "disabled",
+			collector: nil,
+			want:      nil,
+		},
+		{
+			name:      "not mapped parameters",
+			collector: NewExtraParamsCollector(DefaultRegistry, "", 0),
+			want: Params{
+				"h.cr":          "crumb",
+				"dom.res.slow":  "https://www.example.com/image.jpg",
+				"ab.test":       "checkout",
+				"spa.missed":    "1",
+				"plugin.latest": "12345",
+			},
+		},
+		{
+			name:      "denylist",
+			collector: NewExtraParamsCollector(DefaultRegistry, "h.cr, dom.res.*", 0),
+			want: Params{
+				"ab.test":       "checkout",
+				"spa.missed":    "1",
+				"plugin.latest": "12345",
+			},
+		},
+		{
+			name:      "size limit",
+			collector: NewExtraParamsCollector(DefaultRegistry, "h.cr,dom.res.*", 26),
+			want: Params{
+				"ab.test":    "checkout",
+				"spa.missed": "1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.collector.Collect(values))
+		})
+	}
+}
+
+func TestExtraParamsCollector_BeaconParameters(t *testing.T) {
+	collector := NewExtraParamsCollector(DefaultRegistry, "", 0)
+	values := url.Values{}
+	for _, name := range beaconParameters() {
+		values.Set(name, "1")
+	}
+	for _, m := range DefaultRegistry.Mappings() {
+		for _, name := range m.Parameters {
+			values.Set(name, "1")
+		}
+	}
+	require.Empty(t, collector.Collect(values))
+}
+
+func Test_beaconParameters(t *testing.T) {
+	got := beaconParameters()
+	require.Subset(t, got, []string{"created_at", "c.e", "et.e", "u", "err", "nt_nav_type", "navigation", "rt.quit", "scr.mtp", "sb"})
+	require.NotContains(t, got, "pt.lcp")
+}
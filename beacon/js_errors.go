@@ -0,0 +1,280 @@
+package beacon
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultErrorType  = "Error"
+	maxErrorFrames    = 50
+	fingerprintFrames = 5
+	// maxErrorValueLength limits the invalid errors value in the error message, the value comes from the beacon
+	maxErrorValueLength = 256
+)
+
+// The Boomerang Errors plugin source and via codes
+// nolint: gochecknoglobals
+var (
+	errorSources = map[int]string{
+		1: "app",
+		2: "boomerang",
+	}
+	errorVias = map[int]string{
+		1: "app",
+		2: "global_exception_handler",
+		3: "network",
+		4: "console",
+		5: "event_listener",
+		6: "timeout",
+	}
+	fingerprintNumbers = regexp.MustCompile(`\d+`)
+)
+
+// JSError contains the JavaScript error captured by Boomerang Errors plugin
+type JSError struct {
+	Message  string
+	Type     string
+	Code     string
+	Stack    string
+	Source   string
+	Via      string
+	Count    uint32
+	FileName string
+	Line     uint32
+	Column   uint32
+	// Fingerprint groups the same errors, see errorFingerprint
+	Fingerprint uint64
+}
+
+type jsErrorFrame struct {
+	functionName string
+	fileName     string
+	line         uint32
+	column       uint32
+}
+
+// parseErrors parses Boomerang Errors plugin err parameter
+// The value is array of compressed errors serialized as JSURL (~(...)) or JSON
+//
+//	m message, t type (Error when missing), c code, n count (1 when missing),
+//	s source (app when missing), v via (app when missing), f stack frames
+//	frame: f function name, w file name, l line number, c column number
+func parseErrors(value string) ([]JSError, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var data any
+	var err error
+	if strings.HasPrefix(value, "~") {
+		data, err = parseJSURL(value)
+	} else {
+		err = json.Unmarshal([]byte(value), &data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid errors value[%v] length[%v] err[%w]", errorValuePreview(value), len(value), err)
+	}
+	items, ok := data.([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid errors value[%v] length[%v] expected array", errorValuePreview(value), len(value))
+	}
+	var result []JSError
+	for _, item := range items {
+		if obj, ok := item.(map[string]any); ok {
+			result = append(result, newJSError(obj))
+		}
+	}
+	return result, nil
+}
+
+// errorValuePreview truncates the errors value to maxErrorValueLength bytes
+func errorValuePreview(value string) string {
+	if len(value) <= maxErrorValueLength {
+		return value
+	}
+	return strings.ToValidUTF8(value[:maxErrorValueLength], "") + "..."
+}
+
+func newJSError(obj map[string]any) JSError {
+	result := JSError{
+		Message: stringField(obj, "m"),
+		Type:    stringField(obj, "t"),
+		Code:    stringField(obj, "c"),
+		Source:  errorSources[int(numberField(obj, "s", 1))],
+		Via:     errorVias[int(numberField(obj, "v", 1))],
+		Count:   numberField(obj, "n", 1),
+	}
+	if result.Type == "" {
+		result.Type = defaultErrorType
+	}
+	frames := errorFrames(obj["f"])
+	if len(frames) > 0 {
+		result.FileName = frames[0].fileName
+		result.Line = frames[0].line
+		result.Column = frames[0].column
+	}
+	result.Stack = errorStack(frames)
+	result.Fingerprint = errorFingerprint(result.Type, result.Message, frames)
+	return result
+}
+
+func errorFrames(value any) []jsErrorFrame {
+	items, _ := value.([]any)
+	var result []jsErrorFrame
+	for _, item := range items {
+		if len(result) == maxErrorFrames {
+			break
+		}
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		result = append(result, jsErrorFrame{
+			functionName: stringField(obj, "f"),
+			fileName:     stringField(obj, "w"),
+			line:         numberField(obj, "l", 0),
+			column:       numberField(obj, "c", 0),
+		})
+	}
+	return result
+}
+
+// errorStack formats the frames as V8 stack trace lines: "at functionName (fileName:line:column)"
+func errorStack(frames []jsErrorFrame) string {
+	lines := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		location := frame.fileName
+		if frame.line > 0 {
+			location += ":" + strconv.FormatUint(uint64(frame.line), 10)
+		}
+		if frame.column > 0 {
+			location += ":" + strconv.FormatUint(uint64(frame.column), 10)
+		}
+		if frame.functionName == "" {
+			lines = append(lines, "at "+location)
+			continue
+		}
+		lines = append(lines, "at "+frame.functionName+" ("+location+")")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// errorFingerprint hashes the error type, the message without numbers and the top frames
+// The line and column numbers and the file query strings are ignored because they change with every release
+func errorFingerprint(errorType, message string, frames []jsErrorFrame) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(errorType))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(fingerprintNumbers.ReplaceAllString(message, "0")))
+	for i, frame := range frames {
+		if i == fingerprintFrames {
+			break
+		}
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(frame.functionName))
+		_, _ = h.Write([]byte("@"))
+		_, _ = h.Write([]byte(fingerprintFileName(frame.fileName)))
+	}
+	return h.Sum64()
+}
+
+func fingerprintFileName(fileName string) string {
+	u, err := url.Parse(fileName)
+	if err != nil {
+		return fileName
+	}
+	return u.Host + u.Path
+}
+
+func stringField(obj map[string]any, key string) string {
+	switch v := obj[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func numberField(obj map[string]any, key string, defaultValue uint32) uint32 {
+	var v float64
+	switch value := obj[key].(type) {
+	case float64:
+		v = value
+	case string:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return defaultValue
+		}
+		v = parsed
+	default:
+		return defaultValue
+	}
+	if math.IsNaN(v) || v < 0 {
+		return defaultValue
+	}
+	return uint32(math.Min(math.Round(v), math.MaxUint32))
+}
+
+// ErrorEvent contains the webperf_rum_errors row
+type ErrorEvent struct {
+	Created_At       string `json:"created_at"`
+	Hostname         string `json:"hostname"`
+	Url              string `json:"url"`
+	Session_Id       string `json:"session_id,omitempty"`
+	Page_Id          string `json:"page_id,omitempty"`
+	Message          string `json:"message"`
+	Type             string `json:"type"`
+	Code             string `json:"code,omitempty"`
+	Stack            string `json:"stack"`
+	Source           string `json:"source"`
+	Via              string `json:"via"`
+	Count            uint32 `json:"count"`
+	File_Name        string `json:"file_name"`
+	Line_Number      uint32 `json:"line_number"`
+	Column_Number    uint32 `json:"column_number"`
+	Fingerprint      uint64 `json:"fingerprint"`
+	Device_Type      string `json:"device_type"`
+	Browser_Name     string `json:"browser_name"`
+	Operating_System string `json:"operating_system"`
+	Geo_Country_Code string `json:"geo_country_code"`
+}
+
+// NewErrorEvents creates webperf_rum_errors rows from the rum event errors
+func NewErrorEvents(rumEvent RumEvent) []ErrorEvent {
+	sessionID, _ := rumEvent.Columns["session_id"].(string)
+	pageID, _ := rumEvent.Columns["page_id"].(string)
+	result := make([]ErrorEvent, 0, len(rumEvent.Errors))
+	for _, e := range rumEvent.Errors {
+		result = append(result, ErrorEvent{
+			Created_At:       rumEvent.Created_At,
+			Hostname:         rumEvent.Hostname,
+			Url:              rumEvent.Url,
+			Session_Id:       sessionID,
+			Page_Id:          pageID,
+			Message:          e.Message,
+			Type:             e.Type,
+			Code:             e.Code,
+			Stack:            e.Stack,
+			Source:           e.Source,
+			Via:              e.Via,
+			Count:            e.Count,
+			File_Name:        e.FileName,
+			Line_Number:      e.Line,
+			Column_Number:    e.Column,
+			Fingerprint:      e.Fingerprint,
+			Device_Type:      rumEvent.Device_Type,
+			Browser_Name:     rumEvent.Browser_Name,
+			Operating_System: rumEvent.Operating_System,
+			Geo_Country_Code: rumEvent.Geo_Country_Code,
+		})
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package beacon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	errs, err := parseErrors("~(~(m~'Cannot*20read*20properties*20of*20undefined*20*28reading*20*27id*27*29~t~'TypeError~n~3~v~2~f~(~(f~'loadCart~w~'https*3a*2f*2fwww.example.com*2fapp.js*3fv*3d12~l~120~c~15)~(w~'https*3a*2f*2fwww.example.com*2fvendor.js~l~1)))~(m~'Script*20error.~s~2))")
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+
+	require.Equal(t, "Cannot read properties of undefined (reading 'id')", errs[0].Message)
+	require.Equal(t, "TypeError", errs[0].Type)
+	require.Equal(t, uint32(3), errs[0].Count)
+	require.Equal(t, "app", errs[0].Source)
+	require.Equal(t, "global_exception_handler", errs[0].Via)
+	require.Equal(t, "https://www.example.com/app.js?v=12", errs[0].FileName)
+	require.Equal(t, uint32(120), errs[0].Line)
+	require.Equal(t, uint32(15), errs[0].Column)
+	require.Equal(t, "at loadCart (https://www.example.com/app.js?v=12:120:15)\nat https://www.example.com/vendor.js:1", errs[0].Stack)
+
+	require.Equal(t, "Script error.", errs[1].Message)
+	require.Equal(t, "Error", errs[1].Type)
+	require.Equal(t, uint32(1), errs[1].Count)
+	require.Equal(t, "boomerang", errs[1].Source)
+	require.Equal(t, "app", errs[1].Via)
+	require.Empty(t, errs[1].Stack)
+
+	jsonErrs, err := parseErrors(`[{"m":"Script error.","s":2}]`)
+	require.NoError(t, err)
+	require.Equal(t, errs[1], jsonErrs[0])
+
+	empty, err := parseErrors("")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+
+	_, err = parseErrors("~(m~'Oops)")
+	require.Error(t, err)
+	_, err = parseErrors("{invalid")
+	require.Error(t, err)
+
+	_, err = parseErrors("{" + strings.Repeat("a", 1000))
+	require.ErrorContains(t, err, "invalid errors value[{"+strings.Repeat("a", 255)+"...] length[1001]")
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	frames := []jsErrorFrame{
+		{functionName: "loadCart", fileName: "https://www.example.com/app.js?v=12", line: 120, column: 15},
+	}
+	fingerprint := errorFingerprint("TypeError", "Cannot read item 12", frames)
+
+	nextRelease := []jsErrorFrame{
+		{functionName: "loadCart", fileName: "https://www.example.com/app.js?v=13", line: 125, column: 3},
+	}
+	require.Equal(t, fingerprint, errorFingerprint("TypeError", "Cannot read item 7", nextRelease))
+
+	require.NotEqual(t, fingerprint, errorFingerprint("RangeError", "Cannot read item 12", frames))
+	require.NotEqual(t, fingerprint, errorFingerprint("TypeError", "Cannot write item 12", frames))
+	otherFunction := []jsErrorFrame{
+		{functionName: "saveCart", fileName: "https://www.example.com/app.js?v=12", line: 120, column: 15},
+	}
+	require.NotEqual(t, fingerprint, errorFingerprint("TypeError", "Cannot read item 12", otherFunction))
+}
+
+func TestNewErrorEvents(t *testing.T) {
+	rumEvent := RumEvent{
+		Created_At:  "2022-08-27 05:52:55",
+		Hostname:    "www.example.com",
+		Url:         "https://www.example.com/cart",
+		Device_Type: DeviceTypeMobile,
+		Columns: map[string]any{
+			"session_id": "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf",
+			"page_id":    "8ltx0hvo",
+			"t_done":     uint32(80),
+		},
+		Errors: []JSError{
+			{Message: "Oops", Type: "Error", Source: "app", Via: "console", Count: 1, Fingerprint: 42},
+		},
+	}
+
+	require.Equal(t, []ErrorEvent{
+		{
+			Created_At:  "2022-08-27 05:52:55",
+			Hostname:    "www.example.com",
+			Url:         "https://www.example.com/cart",
+			Session_Id:  "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf",
+			Page_Id:     "8ltx0hvo",
+			Message:     "Oops",
+			Type:        "Error",
+			Source:      "app",
+			Via:         "console",
+			Count:       1,
+			Fingerprint: 42,
+			Device_Type: DeviceTypeMobile,
+		},
+	}, NewErrorEvents(rumEvent))
+}
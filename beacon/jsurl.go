@@ -0,0 +1,162 @@
+package beacon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// jsURLParser parses the JSURL format used by BOOMR.utils.serializeForUrl
+// Example: ~(~(m~'Oops*21~n~2~f~(~(l~12~w~'app.js))))
+type jsURLParser struct {
+	value string
+	pos   int
+}
+
+// parseJSURL parses JSURL value into map[string]any, []any, string, float64, bool or nil
+func parseJSURL(value string) (any, error) {
+	p := &jsURLParser{value: value}
+	result, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.value) {
+		return nil, fmt.Errorf("invalid jsurl value[%v] unexpected position[%v]", value, p.pos)
+	}
+	return result, nil
+}
+
+func (p *jsURLParser) parse() (any, error) {
+	if err := p.eat('~'); err != nil {
+		return nil, err
+	}
+	switch p.peek(0) {
+	case '(':
+		p.pos++
+		if p.peek(0) == '~' {
+			return p.parseArray()
+		}
+		return p.parseObject()
+	case '\'':
+		p.pos++
+		return p.decode()
+	default:
+		return p.parseLiteral()
+	}
+}
+
+func (p *jsURLParser) parseArray() (any, error) {
+	result := []any{}
+	if p.peek(1) == ')' {
+		p.pos++
+	} else {
+		for p.peek(0) == '~' {
+			item, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+	}
+	return result, p.eat(')')
+}
+
+func (p *jsURLParser) parseObject() (any, error) {
+	result := map[string]any{}
+	if p.peek(0) != ')' {
+		for {
+			key, err := p.decode()
+			if err != nil {
+				return nil, err
+			}
+			item, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			result[key] = item
+			if p.peek(0) != '~' {
+				break
+			}
+			p.pos++
+		}
+	}
+	return result, p.eat(')')
+}
+
+func (p *jsURLParser) parseLiteral() (any, error) {
+	start := p.pos
+	for p.pos < len(p.value) && p.value[p.pos] != '~' && p.value[p.pos] != ')' {
+		p.pos++
+	}
+	literal := p.value[start:p.pos]
+	switch literal {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	result, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsurl literal[%v] position[%v]", literal, start)
+	}
+	return result, nil
+}
+
+// decode decodes the string escapes: *XX is the character code, **XXXX is the UTF-16 code unit and ! is $
+func (p *jsURLParser) decode() (string, error) {
+	var result strings.Builder
+	for p.pos < len(p.value) {
+		ch := p.value[p.pos]
+		switch ch {
+		case '~', ')':
+			return result.String(), nil
+		case '!':
+			result.WriteByte('$')
+			p.pos++
+		case '*':
+			size := 2
+			if p.peek(1) == '*' {
+				size = 4
+				p.pos++
+			}
+			if p.pos+1+size > len(p.value) {
+				return "", fmt.Errorf("invalid jsurl escape position[%v]", p.pos)
+			}
+			code, err := strconv.ParseUint(p.value[p.pos+1:p.pos+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid jsurl escape position[%v] err[%w]", p.pos, err)
+			}
+			p.pos += 1 + size
+			r := rune(code)
+			if utf16.IsSurrogate(r) && p.peek(0) == '*' && p.peek(1) == '*' && p.pos+6 <= len(p.value) {
+				if low, err := strconv.ParseUint(p.value[p.pos+2:p.pos+6], 16, 32); err == nil {
+					r = utf16.DecodeRune(r, rune(low))
+					p.pos += 6
+				}
+			}
+			result.WriteRune(r)
+		default:
+			result.WriteByte(ch)
+			p.pos++
+		}
+	}
+	return result.String(), nil
+}
+
+func (p *jsURLParser) peek(offset int) byte {
+	if p.pos+offset >= len(p.value) {
+		return 0
+	}
+	return p.value[p.pos+offset]
+}
+
+func (p *jsURLParser) eat(expected byte) error {
+	if p.peek(0) != expected {
+		return fmt.Errorf("invalid jsurl value expected[%c] position[%v]", expected, p.pos)
+	}
+	p.pos++
+	return nil
+}
@@ -0,0 +1,49 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{name: "number", value: "~-1.5", want: -1.5},
+		{name: "literals", value: "~(~true~false~null)", want: []any{true, false, nil}},
+		{name: "string escapes", value: "~'a*20b*21!c**00e9**d83d**de00", want: "a b!$cé😀"},
+		{name: "empty array", value: "~(~)", want: []any{}},
+		{name: "empty object", value: "~()", want: map[string]any{}},
+		{
+			name:  "nested",
+			value: "~(~(m~'Oops~n~2~f~(~(l~12~w~'app.js))))",
+			want: []any{
+				map[string]any{
+					"m": "Oops",
+					"n": float64(2),
+					"f": []any{map[string]any{"l": float64(12), "w": "app.js"}},
+				},
+			},
+		},
+		{name: "missing prefix", value: "(m~'Oops)", wantErr: true},
+		{name: "not closed", value: "~(m~'Oops", wantErr: true},
+		{name: "invalid literal", value: "~abc", wantErr: true},
+		{name: "invalid escape", value: "~'*z1", wantErr: true},
+		{name: "trailing data", value: "~1)", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSURL(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
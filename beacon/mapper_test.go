@@ -2,6 +2,7 @@ package beacon
 
 import (
 	"log"
+	"net/url"
 	"testing"
 
 	"github.com/basicrum/front_basicrum_go/types"
@@ -9,11 +10,11 @@ import (
 )
 
 func TestBasic(t *testing.T) {
-	b := Beacon{
-		Pt_Lcp:     "230",
-		U:          "https//:www.example.com/url",
-		Nt_Con_End: "1653989622106",
-		Nt_Con_St:  "1653989622032",
+	values := url.Values{
+		"pt.lcp":     {"230"},
+		"u":          {"https//:www.example.com/url"},
+		"nt_con_end": {"1653989622106"},
+		"nt_con_st":  {"1653989622032"},
 	}
 	userAgent := "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.82 Safari/537.36"
 
@@ -23,18 +24,18 @@ func TestBasic(t *testing.T) {
 		panic(err)
 	}
 
-	event := &types.Event{UserAgent: userAgent}
-	rE := ConvertToRumEvent(b, event, uaP, nil)
+	event := &types.Event{RequestParameters: values, UserAgent: userAgent}
+	rE := ConvertToRumEvent(FromEvent(event), event, NewUserAgentParser(uaP), nil)
 
-	if rE.Connect_Duration != "74" {
+	if rE.Columns["connect_duration"] != uint16(74) {
 		t.Errorf("Error")
 	}
 
 	log.Printf("rum event[%+v]", rE)
 
-	log.Printf("rum event Cumulative_Layout_Shift[%v]", rE.Cumulative_Layout_Shift)
+	log.Printf("rum event Cumulative_Layout_Shift[%v]", rE.Columns["cumulative_layout_shift"])
 
-	if rE.Cumulative_Layout_Shift != "" {
+	if _, ok := rE.Columns["cumulative_layout_shift"]; ok {
 		t.Errorf("Error")
 	}
 }
@@ -0,0 +1,273 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ColumnType is the ClickHouse column type without Nullable and LowCardinality wrappers
+type ColumnType string
+
+const (
+	// ColumnString String column
+	ColumnString ColumnType = "String"
+	// ColumnUInt8 UInt8 column, the values are rounded and clamped
+	ColumnUInt8 ColumnType = "UInt8"
+	// ColumnUInt16 UInt16 column, the values are rounded and clamped
+	ColumnUInt16 ColumnType = "UInt16"
+	// ColumnUInt32 UInt32 column, the values are rounded and clamped
+	ColumnUInt32 ColumnType = "UInt32"
+	// ColumnFloat32 Float32 column
+	ColumnFloat32 ColumnType = "Float32"
+	// ColumnFloat64 Float64 column
+	ColumnFloat64 ColumnType = "Float64"
+	// ColumnTimers Map(String, UInt32) column, see ParseTimers transform
+	ColumnTimers ColumnType = "Map(String, UInt32)"
+)
+
+// The rejection reasons of the values not fitting the column type
+const (
+	rejectionInvalid    = "invalid"
+	rejectionOutOfRange = "out of range"
+	rejectionTooLong    = "too long"
+
+	maxRejectionValueLength = 64
+)
+
+// nolint: gochecknoglobals
+var unsignedMaxValues = map[ColumnType]float64{
+	ColumnUInt8:  math.MaxUint8,
+	ColumnUInt16: math.MaxUint16,
+	ColumnUInt32: math.MaxUint32,
+}
+
+// ColumnFixedString returns FixedString(length) column type, the longer values are truncated
+func ColumnFixedString(length int) ColumnType {
+	return ColumnType(fmt.Sprintf("FixedString(%d)", length))
+}
+
+// Transform converts the beacon parameter values into the column value
+// The result is string or Timers. Empty string is stored as NULL or the column default
+type Transform func(values []string) any
+
+// Value copies the first parameter
+func Value(values []string) any {
+	return values[0]
+}
+
+// Round rounds the first parameter to integer
+func Round(values []string) any {
+	return RoundFloatParam(values[0])
+}
+
+// Delta returns the milliseconds between the first (start) and the second (end) mark
+func Delta(values []string) any {
+	return calculateDelta(values[0], values[1])
+}
+
+// Split returns the part of the first parameter at index: Split("x", 0) of "1536x864" is "1536"
+func Split(separator string, index int) Transform {
+	return func(values []string) any {
+		parts := strings.Split(values[0], separator)
+		if index >= len(parts) {
+			return ""
+		}
+		return parts[index]
+	}
+}
+
+// ParseTimers parses the first parameter as RT plugin timers, see parseTimers
+func ParseTimers(values []string) any {
+	return parseTimers(values[0])
+}
+
+// Mapping maps beacon parameters into table column
+type Mapping struct {
+	// Column is the webperf_rum_events column name
+	Column string
+	// Parameters are the beacon parameter names passed to the transform
+	Parameters []string
+	// Type is the column type used to convert the value and to validate the table schema
+	Type ColumnType
+	// Transform converts the parameters into column value. Value is used when it is nil
+	Transform Transform
+}
+
+func (m Mapping) value(values url.Values) (any, string) {
+	params := make([]string, len(m.Parameters))
+	for i, name := range m.Parameters {
+		params[i] = values.Get(name)
+	}
+	transform := m.Transform
+	if transform == nil {
+		transform = Value
+	}
+	return m.Type.convert(transform(params))
+}
+
+// Registry contains the beacon parameters to columns mappings
+type Registry struct {
+	mappings []Mapping
+}
+
+// NewRegistry creates registry. The columns must be unique and must not be RumEvent fields
+func NewRegistry(mappings []Mapping) (*Registry, error) {
+	reserved := rumEventColumns()
+	columns := map[string]bool{}
+	for _, m := range mappings {
+		if m.Column == "" || len(m.Parameters) == 0 || m.Type == "" {
+			return nil, fmt.Errorf("invalid mapping column[%v] parameters[%v] type[%v]", m.Column, m.Parameters, m.Type)
+		}
+		if columns[m.Column] || reserved[m.Column] {
+			return nil, fmt.Errorf("duplicated mapping column[%v]", m.Column)
+		}
+		columns[m.Column] = true
+	}
+	return &Registry{mappings: mappings}, nil
+}
+
+// Mappings returns the registered mappings
+func (r *Registry) Mappings() []Mapping {
+	return r.mappings
+}
+
+// Map converts the beacon parameters into typed column values. The empty and invalid values are skipped
+// The rejections contain the reasons of the skipped invalid values and the changed values by column name
+func (r *Registry) Map(values url.Values) (columns map[string]any, rejections Params) {
+	columns = make(map[string]any, len(r.mappings))
+	for _, m := range r.mappings {
+		value, reason := m.value(values)
+		if value != nil {
+			columns[m.Column] = value
+		}
+		if reason == "" {
+			continue
+		}
+		if rejections == nil {
+			rejections = Params{}
+		}
+		rejections[m.Column] = reason
+	}
+	return columns, rejections
+}
+
+// Validate checks that the mapped columns and RumEvent fields exist in the table columns
+// with compatible type. The columns are column types by name
+func (r *Registry) Validate(columns map[string]string) error {
+	var errs []error
+	for _, m := range r.mappings {
+		columnType, ok := columns[m.Column]
+		if !ok {
+			errs = append(errs, fmt.Errorf("mapping column[%v] does not exist", m.Column))
+			continue
+		}
+		if baseColumnType(columnType) != string(m.Type) {
+			errs = append(errs, fmt.Errorf("mapping column[%v] type[%v] does not match table type[%v]", m.Column, m.Type, columnType))
+		}
+	}
+	for column := range rumEventColumns() {
+		if _, ok := columns[column]; !ok {
+			errs = append(errs, fmt.Errorf("rum event column[%v] does not exist", column))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// baseColumnType removes Nullable and LowCardinality wrappers: LowCardinality(Nullable(String)) -> String
+func baseColumnType(columnType string) string {
+	for _, wrapper := range []string{"LowCardinality(", "Nullable("} {
+		if strings.HasPrefix(columnType, wrapper) && strings.HasSuffix(columnType, ")") {
+			columnType = columnType[len(wrapper) : len(columnType)-1]
+		}
+	}
+	return columnType
+}
+
+// convert converts the transformed value into typed column value
+// The reason is not empty when the invalid value is skipped or the value is changed to fit the column type
+func (t ColumnType) convert(value any) (any, string) {
+	switch v := value.(type) {
+	case Timers:
+		if len(v) == 0 {
+			return nil, ""
+		}
+		return v, ""
+	case string:
+		return t.convertString(v)
+	}
+	return nil, ""
+}
+
+func (t ColumnType) convertString(value string) (any, string) {
+	if value == "" {
+		return nil, ""
+	}
+	if maxValue, ok := unsignedMaxValues[t]; ok {
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(v) {
+			return nil, rejection(rejectionInvalid, value)
+		}
+		v = math.Round(v)
+		var reason string
+		if v < 0 || v > maxValue {
+			v = math.Min(math.Max(v, 0), maxValue)
+			reason = rejection(rejectionOutOfRange, value)
+		}
+		return t.unsigned(v), reason
+	}
+	if t == ColumnFloat32 || t == ColumnFloat64 {
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, rejection(rejectionInvalid, value)
+		}
+		if t == ColumnFloat64 {
+			return v, ""
+		}
+		if math.Abs(v) > math.MaxFloat32 {
+			return float32(math.Copysign(math.MaxFloat32, v)), rejection(rejectionOutOfRange, value)
+		}
+		return float32(v), ""
+	}
+	var length int
+	if _, err := fmt.Sscanf(string(t), "FixedString(%d)", &length); err == nil && len(value) > length {
+		return strings.ToValidUTF8(value[:length], ""), rejection(rejectionTooLong, value)
+	}
+	return value, ""
+}
+
+func (t ColumnType) unsigned(value float64) any {
+	switch t {
+	case ColumnUInt8:
+		return uint8(value)
+	case ColumnUInt16:
+		return uint16(value)
+	default:
+		return uint32(value)
+	}
+}
+
+// rejection formats the rejection reason with the value shortened to maxRejectionValueLength
+func rejection(reason, value string) string {
+	if len(value) > maxRejectionValueLength {
+		value = strings.ToValidUTF8(value[:maxRejectionValueLength], "") + "..."
+	}
+	return reason + " value[" + value + "]"
+}
+
+// rumEventColumns returns the json names of RumEvent fields
+func rumEventColumns() map[string]bool {
+	result := map[string]bool{}
+	rumEventType := reflect.TypeOf(RumEvent{})
+	for i := 0; i < rumEventType.NumField(); i++ {
+		name, _, _ := strings.Cut(rumEventType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			result[name] = true
+		}
+	}
+	return result
+}
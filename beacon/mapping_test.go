@@ -0,0 +1,214 @@
+package beacon
+
+import (
+	"encoding/json"
+	"io/fs"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/basicrum/front_basicrum_go/templatemigrations"
+	"github.com/stretchr/testify/require"
+)
+
+// nolint: gochecknoglobals
+var (
+	createColumnPattern = regexp.MustCompile(`^\s+(\w+)\s+(.+?),?$`)
+	dropColumnPattern   = regexp.MustCompile(`DROP COLUMN (\w+)`)
+	columnClausePattern = regexp.MustCompile(` (ALIAS|DEFAULT) .*$`)
+)
+
+// migratedColumns builds webperf_rum_events column types by applying the up migrations
+func migratedColumns(t *testing.T) map[string]string {
+	names, err := fs.Glob(templatemigrations.SQLMigrations, "*_webperf_rum_events.up.sql")
+	require.NoError(t, err)
+	sort.Strings(names)
+
+	result := map[string]string{}
+	for _, name := range names {
+		data, err := fs.ReadFile(templatemigrations.SQLMigrations, name)
+		require.NoError(t, err)
+		migration := string(data)
+		if strings.HasPrefix(migration, "CREATE TABLE") {
+			for _, line := range strings.Split(migration, "\n") {
+				if match := createColumnPattern.FindStringSubmatch(line); match != nil {
+					result[match[1]] = columnClausePattern.ReplaceAllString(match[2], "")
+				}
+			}
+			continue
+		}
+		for _, match := range dropColumnPattern.FindAllStringSubmatch(migration, -1) {
+			delete(result, match[1])
+		}
+		for _, clause := range strings.Split(migration, "ADD COLUMN ")[1:] {
+			clause = strings.TrimRight(clause, ", \n")
+			column, columnType, _ := strings.Cut(clause, " ")
+			result[column] = columnClausePattern.ReplaceAllString(columnType, "")
+		}
+	}
+	return result
+}
+
+func TestDefaultRegistry_Validate(t *testing.T) {
+	columns := migratedColumns(t)
+	require.Equal(t, "Nullable(UInt16)", columns["connect_duration"])
+	require.Equal(t, "Map(String, UInt32)", columns["t_other"])
+
+	require.NoError(t, DefaultRegistry.Validate(columns))
+
+	delete(columns, "dom_sz")
+	delete(columns, "hostname")
+	columns["dom_res"] = "Nullable(String)"
+	err := DefaultRegistry.Validate(columns)
+	require.ErrorContains(t, err, "mapping column[dom_sz] does not exist")
+	require.ErrorContains(t, err, "rum event column[hostname] does not exist")
+	require.ErrorContains(t, err, "mapping column[dom_res] type[UInt16] does not match table type[Nullable(String)]")
+}
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []Mapping
+		wantErr  bool
+	}{
+		{
+			name: "valid",
+			mappings: []Mapping{
+				{Column: "dom_res", Parameters: []string{"dom.res"}, Type: ColumnUInt16},
+			},
+		},
+		{
+			name: "duplicated column",
+			mappings: []Mapping{
+				{Column: "dom_res", Parameters: []string{"dom.res"}, Type: ColumnUInt16},
+				{Column: "dom_res", Parameters: []string{"dom.doms"}, Type: ColumnUInt16},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rum event column",
+			mappings: []Mapping{
+				{Column: "hostname", Parameters: []string{"u"}, Type: ColumnString},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing parameters",
+			mappings: []Mapping{
+				{Column: "dom_res", Type: ColumnUInt16},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(tt.mappings)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRegistry_Map(t *testing.T) {
+	registry, err := NewRegistry([]Mapping{
+		{Column: "string", Parameters: []string{"s"}, Type: ColumnString},
+		{Column: "empty", Parameters: []string{"e"}, Type: ColumnString},
+		{Column: "fixed", Parameters: []string{"f"}, Type: ColumnFixedString(4)},
+		{Column: "uint8", Parameters: []string{"u8"}, Type: ColumnUInt8},
+		{Column: "uint16", Parameters: []string{"u16"}, Type: ColumnUInt16},
+		{Column: "negative", Parameters: []string{"n"}, Type: ColumnUInt32},
+		{Column: "invalid", Parameters: []string{"i"}, Type: ColumnUInt32},
+		{Column: "float", Parameters: []string{"fl"}, Type: ColumnFloat32},
+		{Column: "infinity", Parameters: []string{"inf"}, Type: ColumnFloat64},
+		{Column: "big", Parameters: []string{"big"}, Type: ColumnFloat32},
+		{Column: "round", Parameters: []string{"r"}, Type: ColumnString, Transform: Round},
+		{Column: "width", Parameters: []string{"xy"}, Type: ColumnUInt16, Transform: Split("x", 0)},
+		{Column: "depth", Parameters: []string{"xy"}, Type: ColumnUInt16, Transform: Split("x", 2)},
+		{Column: "delta", Parameters: []string{"st", "end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "timers", Parameters: []string{"t"}, Type: ColumnTimers, Transform: ParseTimers},
+		{Column: "no_timers", Parameters: []string{"nt"}, Type: ColumnTimers, Transform: ParseTimers},
+	})
+	require.NoError(t, err)
+
+	got, rejections := registry.Map(url.Values{
+		"s":   {"value"},
+		"e":   {""},
+		"f":   {"abcdef"},
+		"u8":  {"300"},
+		"u16": {"12.6"},
+		"n":   {"-5"},
+		"i":   {"abc"},
+		"fl":  {"0.0967"},
+		"inf": {"Inf"},
+		"r":   {"1.5"},
+		"xy":  {"1536x864"},
+		"st":  {"1661579573277"},
+		"end": {"1661579573300"},
+		"t":   {"boomr_fb|1907"},
+		"big": {"1e39"},
+	})
+
+	require.Equal(t, map[string]any{
+		"string":   "value",
+		"fixed":    "abcd",
+		"uint8":    uint8(255),
+		"uint16":   uint16(13),
+		"negative": uint32(0),
+		"float":    float32(0.0967),
+		"big":      float32(math.MaxFloat32),
+		"round":    "2",
+		"width":    uint16(1536),
+		"delta":    uint16(23),
+		"timers":   Timers{"boomr_fb": 1907},
+	}, got)
+	require.Equal(t, Params{
+		"fixed":    "too long value[abcdef]",
+		"uint8":    "out of range value[300]",
+		"negative": "out of range value[-5]",
+		"invalid":  "invalid value[abc]",
+		"infinity": "invalid value[Inf]",
+		"big":      "out of range value[1e39]",
+	}, rejections)
+}
+
+func TestRumEvent_MarshalJSON(t *testing.T) {
+	rumEvent := RumEvent{
+		Hostname: "www.example.com",
+		Columns: map[string]any{
+			"dom_res": uint16(35),
+			"ua_plt":  "Win32",
+		},
+	}
+	data, err := json.Marshal(rumEvent)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, "www.example.com", got["hostname"])
+	require.Equal(t, float64(35), got["dom_res"])
+	require.Equal(t, "Win32", got["ua_plt"])
+	require.NotContains(t, got, "Columns")
+
+	data, err = json.Marshal(RumEvent{Hostname: "www.example.com"})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, "www.example.com", got["hostname"])
+}
+
+func TestRumEvent_validate(t *testing.T) {
+	rumEvent := RumEvent{Geo_Country_Code: "USA"}
+	rumEvent.validate()
+	require.Empty(t, rumEvent.Geo_Country_Code)
+	require.Equal(t, Params{"geo_country_code": "invalid value[USA]"}, rumEvent.Rejections)
+
+	rumEvent = RumEvent{Geo_Country_Code: "US"}
+	rumEvent.validate()
+	require.Equal(t, "US", rumEvent.Geo_Country_Code)
+	require.Nil(t, rumEvent.Rejections)
+}
@@ -0,0 +1,109 @@
+package beacon
+
+// DefaultRegistry maps the Boomerang parameters into webperf_rum_events columns
+// Supporting new Boomerang parameter requires new mapping in defaultMappings and migration adding the column
+// nolint: gochecknoglobals
+var DefaultRegistry = mustNewRegistry(defaultMappings())
+
+// nolint: funlen
+func defaultMappings() []Mapping {
+	return []Mapping{
+		// Roundtrip
+		{Column: "session_id", Parameters: []string{"rt.si"}, Type: ColumnFixedString(43)},
+		{Column: "session_length", Parameters: []string{"rt.sl"}, Type: ColumnUInt8},
+		{Column: "t_resp", Parameters: []string{"t_resp"}, Type: ColumnUInt32},
+		{Column: "t_page", Parameters: []string{"t_page"}, Type: ColumnUInt32},
+		{Column: "t_done", Parameters: []string{"t_done"}, Type: ColumnUInt32},
+		{Column: "t_other", Parameters: []string{"t_other"}, Type: ColumnTimers, Transform: ParseTimers},
+
+		// SPA
+		{Column: "route_change_duration", Parameters: []string{"http.initiator", "t_done"}, Type: ColumnUInt32, Transform: RouteChangeDuration},
+
+		// Navigation Timing
+		{Column: "redirect_duration", Parameters: []string{"nt_red_st", "nt_red_end", "nt_red_cnt"}, Type: ColumnUInt16, Transform: RedirectDuration},
+		{Column: "redirects_count", Parameters: []string{"nt_red_cnt"}, Type: ColumnUInt8, Transform: RedirectsCount},
+		{Column: "app_cache_duration", Parameters: []string{"nt_fet_st", "nt_dns_st"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "dns_duration", Parameters: []string{"nt_dns_st", "nt_dns_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "connect_duration", Parameters: []string{"nt_con_st", "nt_con_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "ssl_negotiation_duration", Parameters: []string{"nt_ssl_st", "nt_con_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "request_duration", Parameters: []string{"nt_req_st", "nt_res_st"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "first_byte_duration", Parameters: []string{"nt_nav_st", "nt_res_st"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "response_duration", Parameters: []string{"nt_res_st", "nt_res_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "dom_interactive", Parameters: []string{"nt_nav_st", "nt_domint"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "dom_processing_duration", Parameters: []string{"nt_domloading", "nt_res_end", "nt_domcomp"}, Type: ColumnUInt16, Transform: DomProcessingDuration},
+		{Column: "dom_content_loaded_duration", Parameters: []string{"nt_domcontloaded_st", "nt_domcontloaded_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "load_event_duration", Parameters: []string{"nt_load_st", "nt_load_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "unload_event_duration", Parameters: []string{"nt_unload_st", "nt_unload_end"}, Type: ColumnUInt16, Transform: Delta},
+		{Column: "next_hop_protocol", Parameters: []string{"nt_protocol"}, Type: ColumnString},
+
+		// Paint Timing
+		{Column: "first_paint", Parameters: []string{"pt.fp"}, Type: ColumnUInt16},
+		{Column: "first_contentful_paint", Parameters: []string{"pt.fcp"}, Type: ColumnUInt16},
+		{Column: "largest_contentful_paint", Parameters: []string{"pt.lcp"}, Type: ColumnUInt16},
+		{Column: "lcp_element", Parameters: []string{"pt.lcp.el"}, Type: ColumnString},
+		{Column: "lcp_element_selector", Parameters: []string{"pt.lcp.e"}, Type: ColumnString},
+		{Column: "lcp_resource_url", Parameters: []string{"pt.lcp.src"}, Type: ColumnString},
+		{Column: "lcp_size", Parameters: []string{"pt.lcp.s"}, Type: ColumnUInt32, Transform: Round},
+		{Column: "lcp_ttfb", Parameters: []string{"pt.lcp.ttfb"}, Type: ColumnUInt16, Transform: Round},
+		{Column: "lcp_resource_load_delay", Parameters: []string{"pt.lcp.rld"}, Type: ColumnUInt16, Transform: Round},
+		{Column: "lcp_resource_load_time", Parameters: []string{"pt.lcp.rlt"}, Type: ColumnUInt16, Transform: Round},
+		{Column: "lcp_render_delay", Parameters: []string{"pt.lcp.erd"}, Type: ColumnUInt16, Transform: Round},
+
+		// Event Timing
+		{Column: "first_input_delay", Parameters: []string{"et.fid"}, Type: ColumnUInt16},
+		{Column: "interaction_to_next_paint", Parameters: []string{"et.inp"}, Type: ColumnUInt16, Transform: Round},
+		{Column: "inp_target", Parameters: []string{"et.inp.e"}, Type: ColumnString},
+		{Column: "inp_time", Parameters: []string{"et.inp.t"}, Type: ColumnUInt32, Transform: Round},
+
+		// Continuity
+		{Column: "cumulative_layout_shift", Parameters: []string{"c.cls"}, Type: ColumnFloat32},
+		{Column: "cls_source", Parameters: []string{"c.cls.d"}, Type: ColumnString},
+		{Column: "cls_time", Parameters: []string{"c.cls.tm"}, Type: ColumnUInt32, Transform: Round},
+
+		// Mobile
+		{Column: "mob_etype", Parameters: []string{"mob.etype"}, Type: ColumnString},
+		{Column: "mob_dl", Parameters: []string{"mob.dl"}, Type: ColumnUInt16, Transform: Round},
+		{Column: "mob_rtt", Parameters: []string{"mob.rtt"}, Type: ColumnUInt16},
+		{Column: "data_saver_on", Parameters: []string{"net.sd"}, Type: ColumnUInt8},
+
+		// Memory
+		{Column: "dom_res", Parameters: []string{"dom.res"}, Type: ColumnUInt16},
+		{Column: "dom_doms", Parameters: []string{"dom.doms"}, Type: ColumnUInt16},
+		{Column: "mem_total", Parameters: []string{"mem.total"}, Type: ColumnUInt32},
+		{Column: "mem_limit", Parameters: []string{"mem.limit"}, Type: ColumnUInt32},
+		{Column: "mem_used", Parameters: []string{"mem.used"}, Type: ColumnUInt32},
+		{Column: "mem_lsln", Parameters: []string{"mem.lsln"}, Type: ColumnUInt32},
+		{Column: "mem_ssln", Parameters: []string{"mem.ssln"}, Type: ColumnUInt32},
+		{Column: "mem_lssz", Parameters: []string{"mem.lssz"}, Type: ColumnUInt32},
+		{Column: "screen_width", Parameters: []string{"scr.xy"}, Type: ColumnUInt16, Transform: Split("x", 0)},
+		{Column: "screen_height", Parameters: []string{"scr.xy"}, Type: ColumnUInt16, Transform: Split("x", 1)},
+		{Column: "scr_bpp", Parameters: []string{"scr.bpp"}, Type: ColumnString},
+		{Column: "scr_orn", Parameters: []string{"scr.orn"}, Type: ColumnString},
+		{Column: "cpu_cnc", Parameters: []string{"cpu.cnc"}, Type: ColumnUInt8},
+		{Column: "dom_ln", Parameters: []string{"dom.ln"}, Type: ColumnUInt16},
+		{Column: "dom_sz", Parameters: []string{"dom.sz"}, Type: ColumnUInt16},
+		{Column: "dom_ck", Parameters: []string{"dom.ck"}, Type: ColumnUInt16},
+		{Column: "dom_img", Parameters: []string{"dom.img"}, Type: ColumnUInt16},
+		{Column: "dom_img_uniq", Parameters: []string{"dom.img.uniq"}, Type: ColumnUInt16},
+		{Column: "dom_script", Parameters: []string{"dom.script"}, Type: ColumnUInt16},
+		{Column: "dom_script_ext", Parameters: []string{"dom.script.ext"}, Type: ColumnUInt16},
+		{Column: "dom_iframe", Parameters: []string{"dom.iframe"}, Type: ColumnUInt16},
+		{Column: "dom_link", Parameters: []string{"dom.link"}, Type: ColumnUInt16},
+		{Column: "dom_link_css", Parameters: []string{"dom.link.css"}, Type: ColumnUInt16},
+
+		// Misc
+		{Column: "boomerang_version", Parameters: []string{"v"}, Type: ColumnString},
+		{Column: "visibility_state", Parameters: []string{"vis.st"}, Type: ColumnString},
+		{Column: "page_id", Parameters: []string{"pid"}, Type: ColumnFixedString(8)},
+		{Column: "ua_vnd", Parameters: []string{"ua.vnd"}, Type: ColumnString},
+		{Column: "ua_plt", Parameters: []string{"ua.plt"}, Type: ColumnString},
+	}
+}
+
+func mustNewRegistry(mappings []Mapping) *Registry {
+	result, err := NewRegistry(mappings)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package beacon
+
+import (
+	"strconv"
+)
+
+// The Navigation Timing phases are mapped in defaultMappings
+//
+//	redirect  nt_red_st -> nt_red_end
+//	app cache nt_fet_st -> nt_dns_st
+//	dns       nt_dns_st -> nt_dns_end
+//	connect   nt_con_st -> nt_con_end (includes the TLS handshake)
+//	tls       nt_ssl_st -> nt_con_end
+//	request   nt_req_st -> nt_res_st
+//	response  nt_res_st -> nt_res_end
+//	dom       nt_domloading -> nt_domcomp
+
+// RedirectDuration transforms nt_red_st, nt_red_end and nt_red_cnt into redirect duration
+// The duration is zero when there are no redirects.
+// The cross-origin redirects are counted as zero by the browser and their marks are not exposed
+func RedirectDuration(values []string) any {
+	if result := calculateDelta(values[0], values[1]); result != "" {
+		return result
+	}
+	if redirectsCount(values[2]) == "0" {
+		return "0"
+	}
+	return ""
+}
+
+// RedirectsCount transforms nt_red_cnt into redirects count. The missing count is zero
+func RedirectsCount(values []string) any {
+	return redirectsCount(values[0])
+}
+
+// DomProcessingDuration transforms nt_domloading, nt_res_end and nt_domcomp into dom processing duration
+// The response end is used when dom loading mark is missing
+func DomProcessingDuration(values []string) any {
+	domStart := values[0]
+	if markValue(domStart) == 0 {
+		domStart = values[1]
+	}
+	return calculateDelta(domStart, values[2])
+}
+
+func redirectsCount(value string) string {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return "0"
+	}
+	return strconv.Itoa(count)
+}
+
+// calculateDelta returns the milliseconds between two marks
+// The marks are epoch milliseconds. Boomerang sends zero or omits the mark when it is not available
+func calculateDelta(p1 string, p2 string) string {
+	start := markValue(p1)
+	end := markValue(p2)
+	if start == 0 || end == 0 {
+		return ""
+	}
+
+	v := end - start
+
+	if v < 0 {
+		v = 0
+	}
+
+	return strconv.FormatInt(v, 10)
+}
+
+func markValue(mark string) int64 {
+	result, err := strconv.ParseInt(mark, 10, 64)
+	if err != nil || result < 0 {
+		return 0
+	}
+	return result
+}
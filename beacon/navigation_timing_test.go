@@ -0,0 +1,128 @@
+package beacon
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNavigationTimingMappings(t *testing.T) {
+	tests := []struct {
+		name           string
+		values         url.Values
+		want           map[string]any
+		wantRejections Params
+	}{
+		{
+			name: "all marks",
+			values: url.Values{
+				"nt_nav_st":            {"1661579573277"},
+				"nt_red_st":            {"1661579573277"},
+				"nt_red_end":           {"1661579573280"},
+				"nt_red_cnt":           {"1"},
+				"nt_fet_st":            {"1661579573284"},
+				"nt_dns_st":            {"1661579573355"},
+				"nt_dns_end":           {"1661579573355"},
+				"nt_con_st":            {"1661579573355"},
+				"nt_ssl_st":            {"1661579573404"},
+				"nt_con_end":           {"1661579573518"},
+				"nt_req_st":            {"1661579573519"},
+				"nt_res_st":            {"1661579574577"},
+				"nt_res_end":           {"1661579574594"},
+				"nt_domloading":        {"1661579574590"},
+				"nt_domint":            {"1661579575271"},
+				"nt_domcontloaded_st":  {"1661579575271"},
+				"nt_domcontloaded_end": {"1661579575284"},
+				"nt_domcomp":           {"1661579575479"},
+				"nt_load_st":           {"1661579575479"},
+				"nt_load_end":          {"1661579575484"},
+				"nt_unload_st":         {"1661579574600"},
+				"nt_unload_end":        {"1661579574601"},
+			},
+			want: map[string]any{
+				"redirect_duration":           uint16(3),
+				"redirects_count":             uint8(1),
+				"app_cache_duration":          uint16(71),
+				"dns_duration":                uint16(0),
+				"connect_duration":            uint16(163),
+				"ssl_negotiation_duration":    uint16(114),
+				"request_duration":            uint16(1058),
+				"first_byte_duration":         uint16(1300),
+				"response_duration":           uint16(17),
+				"dom_interactive":             uint16(1994),
+				"dom_processing_duration":     uint16(889),
+				"dom_content_loaded_duration": uint16(13),
+				"load_event_duration":         uint16(5),
+				"unload_event_duration":       uint16(1),
+			},
+		},
+		{
+			name: "http without redirects and previous page",
+			values: url.Values{
+				"nt_nav_st":     {"1661579573277"},
+				"nt_red_st":     {"0"},
+				"nt_red_end":    {"0"},
+				"nt_red_cnt":    {"0"},
+				"nt_con_st":     {"1661579573355"},
+				"nt_ssl_st":     {"0"},
+				"nt_con_end":    {"1661579573400"},
+				"nt_res_end":    {"1661579574594"},
+				"nt_domcomp":    {"1661579575479"},
+				"nt_unload_st":  {"0"},
+				"nt_unload_end": {"0"},
+			},
+			want: map[string]any{
+				"redirect_duration":       uint16(0),
+				"redirects_count":         uint8(0),
+				"connect_duration":        uint16(45),
+				"dom_processing_duration": uint16(885),
+			},
+		},
+		{
+			name:   "missing marks",
+			values: url.Values{},
+			want: map[string]any{
+				"redirect_duration": uint16(0),
+				"redirects_count":   uint8(0),
+			},
+		},
+		{
+			name: "redirect marks are not exposed",
+			values: url.Values{
+				"nt_red_cnt": {"2"},
+			},
+			want: map[string]any{
+				"redirects_count": uint8(2),
+			},
+		},
+		{
+			name: "out of range values",
+			values: url.Values{
+				"nt_red_cnt":  {"300"},
+				"nt_nav_st":   {"1661579573277"},
+				"nt_res_st":   {"1661579673277"},
+				"nt_dns_st":   {"1661579573355"},
+				"nt_dns_end":  {"1661579573300"},
+				"nt_load_st":  {"invalid"},
+				"nt_load_end": {"1661579573300"},
+			},
+			want: map[string]any{
+				"redirects_count":     uint8(255),
+				"dns_duration":        uint16(0),
+				"first_byte_duration": uint16(65535),
+			},
+			wantRejections: Params{
+				"redirects_count":     "out of range value[300]",
+				"first_byte_duration": "out of range value[100000]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, rejections := DefaultRegistry.Map(tt.values)
+			require.Equal(t, tt.want, columns)
+			require.Equal(t, tt.wantRejections, rejections)
+		})
+	}
+}
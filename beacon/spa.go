@@ -0,0 +1,10 @@
+package beacon
+
+// RouteChangeDuration transforms http.initiator and t_done into SPA soft navigation duration
+// The duration is from the route change start until the page is complete, it is empty for the other events
+func RouteChangeDuration(values []string) any {
+	if values[0] != EventTypeSpa {
+		return ""
+	}
+	return values[1]
+}
@@ -0,0 +1,37 @@
+package beacon
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	timersSeparator     = ","
+	timerValueSeparator = "|"
+)
+
+// Timers contains the custom timers in milliseconds by name
+type Timers map[string]uint32
+
+// parseTimers parses the RT plugin t_other custom timers "t_domloaded|2008,boomr_fb|1907,custom0|12.5"
+// The values are rounded to milliseconds. The invalid and negative timers are skipped
+func parseTimers(value string) Timers {
+	result := Timers{}
+	if value == "" {
+		return result
+	}
+	for _, timer := range strings.Split(value, timersSeparator) {
+		name, duration, found := strings.Cut(timer, timerValueSeparator)
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(duration), 64)
+		if err != nil || v < 0 || math.IsNaN(v) {
+			continue
+		}
+		result[name] = uint32(math.Min(math.Round(v), math.MaxUint32))
+	}
+	return result
+}
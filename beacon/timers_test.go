@@ -0,0 +1,47 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  Timers
+	}{
+		{
+			name:  "boomerang timers",
+			value: "t_domloaded|2008,boomerang|8,boomr_fb|1907,boomr_ld|1548,boomr_lat|359",
+			want: Timers{
+				"t_domloaded": 2008,
+				"boomerang":   8,
+				"boomr_fb":    1907,
+				"boomr_ld":    1548,
+				"boomr_lat":   359,
+			},
+		},
+		{
+			name:  "custom float timer",
+			value: "custom0|12.5,custom1|3.2",
+			want:  Timers{"custom0": 13, "custom1": 3},
+		},
+		{
+			name:  "invalid timers are skipped",
+			value: "custom0,|5,custom1|abc,custom2|-1,custom3|7",
+			want:  Timers{"custom3": 7},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  Timers{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, parseTimers(tt.value))
+		})
+	}
+}
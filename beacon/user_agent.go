@@ -0,0 +1,42 @@
+package beacon
+
+import (
+	"sync/atomic"
+
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+// UserAgent contains the parsed user agent details
+type UserAgent struct {
+	Client *uaparser.Client
+}
+
+// UserAgentParser parses the user agent string
+type UserAgentParser interface {
+	Parse(userAgent string) *UserAgent
+}
+
+// RegexUserAgentParser parses the user agent with uap-go regular expressions
+// The regular expressions can be swapped while the parser is in use
+type RegexUserAgentParser struct {
+	parser atomic.Pointer[uaparser.Parser]
+}
+
+// NewUserAgentParser creates uap-go user agent parser
+func NewUserAgentParser(parser *uaparser.Parser) *RegexUserAgentParser {
+	result := &RegexUserAgentParser{}
+	result.Swap(parser)
+	return result
+}
+
+// Swap replaces the uap-go parser used for the next user agents
+func (p *RegexUserAgentParser) Swap(parser *uaparser.Parser) {
+	p.parser.Store(parser)
+}
+
+// Parse returns the browser, operating system and device of the user agent
+func (p *RegexUserAgentParser) Parse(userAgent string) *UserAgent {
+	return &UserAgent{
+		Client: p.parser.Load().Parse(userAgent),
+	}
+}
@@ -0,0 +1,93 @@
+package beacon
+
+import (
+	"strconv"
+)
+
+const (
+	xhrTypeFetch       = "fetch"
+	xhrTypeXhr         = "xhr"
+	xhrDefaultMethod   = "GET"
+	boomerangFetchType = "f"
+)
+
+// XhrRequest contains the XMLHttpRequest or fetch request from AutoXHR plugin beacon
+type XhrRequest struct {
+	// Url is the request url, Boomerang sends it as u parameter
+	Url string
+	// Page_Url is the url of the page sending the request, Boomerang sends it as pgu parameter
+	Page_Url string
+	Method   string
+	// Type is xhr or fetch
+	Type string
+	// Status is the HTTP status or negative error code. Boomerang sends it only for the failed requests
+	Status *int16
+}
+
+func newXhrRequest(b Beacon) *XhrRequest {
+	result := &XhrRequest{
+		Url:      b.U,
+		Page_Url: b.Pgu,
+		Method:   b.Http_Method,
+		Type:     xhrTypeXhr,
+	}
+	if result.Page_Url == "" {
+		result.Page_Url = b.U
+	}
+	if result.Method == "" {
+		result.Method = xhrDefaultMethod
+	}
+	if b.Http_Type == boomerangFetchType {
+		result.Type = xhrTypeFetch
+	}
+	if status, err := strconv.ParseInt(b.Http_Errno, 10, 16); err == nil {
+		result.Status = new(int16)
+		*result.Status = int16(status)
+	}
+	return result
+}
+
+// XhrEvent contains the webperf_rum_xhr row
+type XhrEvent struct {
+	Created_At       string  `json:"created_at"`
+	Hostname         string  `json:"hostname"`
+	Page_Url         string  `json:"page_url"`
+	Request_Url      string  `json:"request_url"`
+	Request_Method   string  `json:"request_method"`
+	Request_Type     string  `json:"request_type"`
+	Status           *int16  `json:"status,omitempty"`
+	Duration         *uint32 `json:"duration,omitempty"`
+	Session_Id       string  `json:"session_id,omitempty"`
+	Page_Id          string  `json:"page_id,omitempty"`
+	Device_Type      string  `json:"device_type"`
+	Browser_Name     string  `json:"browser_name"`
+	Operating_System string  `json:"operating_system"`
+	Geo_Country_Code string  `json:"geo_country_code"`
+	User_Agent       string  `json:"user_agent,omitempty"`
+}
+
+// NewXhrEvent creates webperf_rum_xhr row from xhr rum event. The duration is t_done of the request
+func NewXhrEvent(rumEvent RumEvent) XhrEvent {
+	result := XhrEvent{
+		Created_At:       rumEvent.Created_At,
+		Hostname:         rumEvent.Hostname,
+		Page_Url:         rumEvent.Url,
+		Device_Type:      rumEvent.Device_Type,
+		Browser_Name:     rumEvent.Browser_Name,
+		Operating_System: rumEvent.Operating_System,
+		Geo_Country_Code: rumEvent.Geo_Country_Code,
+		User_Agent:       rumEvent.User_Agent,
+	}
+	if xhr := rumEvent.Xhr; xhr != nil {
+		result.Request_Url = xhr.Url
+		result.Request_Method = xhr.Method
+		result.Request_Type = xhr.Type
+		result.Status = xhr.Status
+	}
+	if duration, ok := rumEvent.Columns["t_done"].(uint32); ok {
+		result.Duration = &duration
+	}
+	result.Session_Id, _ = rumEvent.Columns["session_id"].(string)
+	result.Page_Id, _ = rumEvent.Columns["page_id"].(string)
+	return result
+}
@@ -0,0 +1,151 @@
+package beacon
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+func TestConvertToRumEvent_Initiator(t *testing.T) {
+	uaP, err := uaparser.New("../assets/uaparser_regexes.yaml")
+	require.NoError(t, err)
+	tests := []struct {
+		name      string
+		values    url.Values
+		eventType string
+		url       string
+		hostname  string
+		xhr       *XhrRequest
+		routeTime any
+	}{
+		{
+			name: "page load",
+			values: url.Values{
+				"u":      {"https://www.example.com/"},
+				"t_done": {"1200"},
+			},
+			eventType: EventTypeVisitPage,
+			url:       "https://www.example.com/",
+			hostname:  "www.example.com",
+		},
+		{
+			name: "spa hard navigation",
+			values: url.Values{
+				"u":              {"https://www.example.com/"},
+				"http.initiator": {"spa_hard"},
+				"t_done":         {"1200"},
+			},
+			eventType: EventTypeSpaHard,
+			url:       "https://www.example.com/",
+			hostname:  "www.example.com",
+		},
+		{
+			name: "spa soft navigation",
+			values: url.Values{
+				"u":              {"https://www.example.com/cart"},
+				"http.initiator": {"spa"},
+				"t_done":         {"350"},
+			},
+			eventType: EventTypeSpa,
+			url:       "https://www.example.com/cart",
+			hostname:  "www.example.com",
+			routeTime: uint32(350),
+		},
+		{
+			name: "fetch request",
+			values: url.Values{
+				"u":              {"https://api.example.com/cart?id=1"},
+				"pgu":            {"https://www.example.com/cart"},
+				"http.initiator": {"xhr"},
+				"http.method":    {"POST"},
+				"http.type":      {"f"},
+				"http.errno":     {"503"},
+				"t_done":         {"80"},
+			},
+			eventType: EventTypeXhr,
+			url:       "https://www.example.com/cart",
+			hostname:  "www.example.com",
+			xhr: &XhrRequest{
+				Url:      "https://api.example.com/cart?id=1",
+				Page_Url: "https://www.example.com/cart",
+				Method:   "POST",
+				Type:     "fetch",
+				Status:   status(503),
+			},
+		},
+		{
+			name: "xhr request without page url",
+			values: url.Values{
+				"u":              {"https://www.example.com/api"},
+				"http.initiator": {"xhr"},
+				"http.errno":     {"invalid"},
+			},
+			eventType: EventTypeXhr,
+			url:       "https://www.example.com/api",
+			hostname:  "www.example.com",
+			xhr: &XhrRequest{
+				Url:      "https://www.example.com/api",
+				Page_Url: "https://www.example.com/api",
+				Method:   "GET",
+				Type:     "xhr",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &types.Event{RequestParameters: tt.values}
+			rumEvent := ConvertToRumEvent(FromEvent(event), event, NewUserAgentParser(uaP), nil)
+			require.Equal(t, tt.eventType, rumEvent.Event_Type)
+			require.Equal(t, tt.url, rumEvent.Url)
+			require.Equal(t, tt.hostname, rumEvent.Hostname)
+			require.Equal(t, tt.xhr, rumEvent.Xhr)
+			require.Equal(t, tt.routeTime, rumEvent.Columns["route_change_duration"])
+		})
+	}
+}
+
+func TestNewXhrEvent(t *testing.T) {
+	rumEvent := RumEvent{
+		Created_At:   "2022-08-27 05:52:55",
+		Hostname:     "www.example.com",
+		Url:          "https://www.example.com/cart",
+		Device_Type:  DeviceTypeDesktop,
+		Browser_Name: "Chrome",
+		Event_Type:   EventTypeXhr,
+		Columns: map[string]any{
+			"t_done":     uint32(80),
+			"session_id": "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf",
+		},
+		Xhr: &XhrRequest{
+			Url:    "https://api.example.com/cart",
+			Method: "POST",
+			Type:   "fetch",
+			Status: status(503),
+		},
+	}
+
+	require.Equal(t, XhrEvent{
+		Created_At:     "2022-08-27 05:52:55",
+		Hostname:       "www.example.com",
+		Page_Url:       "https://www.example.com/cart",
+		Request_Url:    "https://api.example.com/cart",
+		Request_Method: "POST",
+		Request_Type:   "fetch",
+		Status:         status(503),
+		Duration:       duration(80),
+		Session_Id:     "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf",
+		Device_Type:    DeviceTypeDesktop,
+		Browser_Name:   "Chrome",
+	}, NewXhrEvent(rumEvent))
+}
+
+func status(value int16) *int16 {
+	return &value
+}
+
+func duration(value uint32) *uint32 {
+	return &value
+}
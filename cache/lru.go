@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Stats contains the cache usage statistics
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	Size   int
+}
+
+// HitRatio returns the ratio of hits to all lookups
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is bounded concurrency safe least recently used cache with time to live
+// The zero size cache is disabled - every lookup is a miss and nothing is stored
+type LRU[K comparable, V any] struct {
+	cache  *lru.Cache
+	ttl    time.Duration
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	now    func() time.Time
+}
+
+// NewLRU creates LRU cache with maximum size and time to live of the entries
+// The entries never expire when ttl is zero
+func NewLRU[K comparable, V any](size int, ttl time.Duration) (*LRU[K, V], error) {
+	result := &LRU[K, V]{
+		ttl: ttl,
+		now: time.Now,
+	}
+	if size <= 0 {
+		return result, nil
+	}
+	c, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	result.cache = c
+	return result, nil
+}
+
+// Get returns the value by key if it is found and not expired
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c.cache == nil {
+		c.misses.Add(1)
+		return zero, false
+	}
+	item, ok := c.cache.Get(key)
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+	e, ok := item.(entry[V])
+	if !ok || c.expired(e) {
+		c.cache.Remove(key)
+		c.misses.Add(1)
+		return zero, false
+	}
+	c.hits.Add(1)
+	return e.value, true
+}
+
+// Add stores the value by key
+func (c *LRU[K, V]) Add(key K, value V) {
+	if c.cache == nil {
+		return
+	}
+	e := entry[V]{value: value}
+	if c.ttl > 0 {
+		e.expiresAt = c.now().Add(c.ttl)
+	}
+	c.cache.Add(key, e)
+}
+
+// Purge removes all the entries
+func (c *LRU[K, V]) Purge() {
+	if c.cache == nil {
+		return
+	}
+	c.cache.Purge()
+}
+
+// Stats returns the cache usage statistics
+func (c *LRU[K, V]) Stats() Stats {
+	result := Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if c.cache != nil {
+		result.Size = c.cache.Len()
+	}
+	return result
+}
+
+func (c *LRU[K, V]) expired(e entry[V]) bool {
+	return !e.expiresAt.IsZero() && c.now().After(e.expiresAt)
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_GetAdd(t *testing.T) {
+	c, err := NewLRU[string, int](2, 0)
+	require.NoError(t, err)
+
+	_, ok := c.Get("a")
+	require.False(t, ok)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	value, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	// "b" is the least recently used
+	c.Add("c", 3)
+	_, ok = c.Get("b")
+	require.False(t, ok)
+
+	require.Equal(t, Stats{Hits: 1, Misses: 2, Size: 2}, c.Stats())
+	require.InDelta(t, 1.0/3.0, c.Stats().HitRatio(), 0.0001)
+}
+
+func TestLRU_Expired(t *testing.T) {
+	c, err := NewLRU[string, int](2, time.Minute)
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Equal(t, 0, c.Stats().Size)
+}
+
+func TestLRU_Purge(t *testing.T) {
+	c, err := NewLRU[string, int](2, 0)
+	require.NoError(t, err)
+
+	c.Add("a", 1)
+	c.Purge()
+	_, ok := c.Get("a")
+	require.False(t, ok)
+	require.Equal(t, 0, c.Stats().Size)
+}
+
+func TestLRU_Disabled(t *testing.T) {
+	c, err := NewLRU[string, int](0, time.Minute)
+	require.NoError(t, err)
+
+	c.Add("a", 1)
+	c.Purge()
+	_, ok := c.Get("a")
+	require.False(t, ok)
+	require.Equal(t, Stats{Misses: 1}, c.Stats())
+	require.Equal(t, float64(0), Stats{}.HitRatio())
+}
+
+func TestLRU_Concurrent(t *testing.T) {
+	c, err := NewLRU[int, int](10, time.Minute)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Add(j%20, i)
+				c.Get(j % 20)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	require.Equal(t, uint64(1000), stats.Hits+stats.Misses)
+	require.LessOrEqual(t, stats.Size, 10)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/basicrum/front_basicrum_go/config"
+	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/deadletter"
+)
+
+const usage = `Usage: deadletter <command> [flags]
+
+Inspects and resubmits the rows which failed to be inserted into ClickHouse.
+The storage and the database are configured with the server environment variables.
+
+Commands:
+  list      prints the stored dead letters as NDJSON
+  resubmit  inserts the stored rows into their tables and removes the inserted dead letters
+
+Flags:
+`
+
+func main() {
+	flags := flag.NewFlagSet("deadletter", flag.ExitOnError)
+	limit := flags.Int("limit", 0, "maximum number of listed dead letters, 0 lists all")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+
+	if command != "list" && command != "resubmit" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := run(command, *limit); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(command string, limit int) error {
+	sConf, err := config.GetStartupConfig()
+	if err != nil {
+		return err
+	}
+	database, err := dao.FromConfig(sConf)
+	if err != nil {
+		return err
+	}
+	conn, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	// the resubmitted rows of the new hostnames are migrated like in the server
+	daoService := database.DAO(conn, database.TenantMigrator())
+	defer daoService.Close()
+
+	store, err := database.DeadLetter(deadletter.Storage(sConf.DeadLetter.Storage), sConf.DeadLetter.Path, conn)
+	if err != nil {
+		return err
+	}
+	if command == "list" {
+		return list(store, limit)
+	}
+	return resubmit(store, daoService)
+}
+
+func list(store deadletter.IStore, limit int) error {
+	entries, err := store.List(limit)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resubmit(store deadletter.IStore, daoService *dao.DAO) error {
+	submitted, err := store.Resubmit(func(entry deadletter.Entry) error {
+		return daoService.InsertRows(entry.Table_Name, []byte(entry.Data))
+	})
+	log.Printf("resubmitted dead letters[%v]", submitted)
+	return err
+}
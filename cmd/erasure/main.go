@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/basicrum/front_basicrum_go/backup"
+	"github.com/basicrum/front_basicrum_go/config"
+	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/deadletter"
+	"github.com/basicrum/front_basicrum_go/erasure"
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const usage = `Usage: erasure <command> [-session-ids <id1,id2>] [-page-ids <id1,id2>]
+
+Deletes the visitor data of the sessions and the page views from the database tables, the dead letters and the backup files.
+The database and the backup are configured with the server environment variables.
+
+Commands:
+  delete  starts the database mutations and rewrites the backup files
+  status  prints the remaining rows and the unfinished mutations of the tables
+
+Flags:
+`
+
+func main() {
+	flags := flag.NewFlagSet("erasure", flag.ExitOnError)
+	sessionIDs := flags.String("session-ids", "", "comma separated session ids (rt.si)")
+	pageIDs := flags.String("page-ids", "", "comma separated page ids (pid)")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+
+	if command != "delete" && command != "status" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	request := types.ErasureRequest{
+		SessionIDs: splitIDs(*sessionIDs),
+		PageIDs:    splitIDs(*pageIDs),
+	}
+	if err := run(command, request); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(command string, request types.ErasureRequest) error {
+	sConf, err := config.GetStartupConfig()
+	if err != nil {
+		return err
+	}
+	database, err := dao.FromConfig(sConf)
+	if err != nil {
+		return err
+	}
+	conn, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	// the command reads the existing hostname tables, so it does not migrate the tables of the new hostnames
+	daoService := database.DAO(conn, nil)
+	defer daoService.Close()
+
+	backupDirectory := ""
+	if sConf.Backup.Enabled {
+		backupDirectory = backup.ArchiveDirectory(sConf.Backup.Directory)
+	}
+	deadLetterStore, err := database.DeadLetter(deadletter.Storage(sConf.DeadLetter.Storage), sConf.DeadLetter.Path, conn)
+	if err != nil {
+		return err
+	}
+	service, err := erasure.New(daoService, deadLetterStore, backupDirectory)
+	if err != nil {
+		return err
+	}
+
+	var result any
+	if command == "delete" {
+		result, err = service.Erase(request)
+	} else {
+		result, err = service.Status(request)
+	}
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(result)
+}
+
+func splitIDs(value string) []string {
+	var result []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			result = append(result, id)
+		}
+	}
+	return result
+}
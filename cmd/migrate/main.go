@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/basicrum/front_basicrum_go/config"
+	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const usage = `Usage: migrate <command> [flags]
+
+Manages the database migrations of the tables.
+The database is configured with the server environment variables.
+
+Commands:
+  up            applies the pending migrations
+  down [n]      rolls back the last n applied migrations, 1 by default
+  status        prints the migrations with the applied time as NDJSON
+  mark-applied  marks the pending migrations as applied without running them
+
+Flags:
+`
+
+func main() {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "prints the SQL with the table prefix without changing the database")
+	hostname := flags.String("hostname", "", "migrates the tables of the hostname with the persistance strategy")
+	name := flags.String("name", "", "the migration marked by mark-applied, all pending migrations by default")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	args := os.Args[2:]
+	count := ""
+	if command == "down" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		count, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if count == "" {
+		count = flags.Arg(0)
+	}
+
+	n := 1
+	switch command {
+	case "up", "status", "mark-applied":
+	case "down":
+		if count != "" {
+			value, err := strconv.Atoi(count)
+			if err != nil || value < 1 {
+				log.Fatalf("invalid number of migrations[%v]", count)
+			}
+			n = value
+		}
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := run(command, n, *name, *hostname, *dryRun); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(command string, n int, name, hostname string, dryRun bool) error {
+	sConf, err := config.GetStartupConfig()
+	if err != nil {
+		return err
+	}
+	database, err := dao.FromConfig(sConf)
+	if err != nil {
+		return err
+	}
+	migrationDAO := database.Migration()
+	if hostname != "" {
+		migrationDAO = migrationDAO.Tenant(database.Strategy().Location(sConf.Database.TablePrefix, hostname))
+	}
+
+	var result []types.MigrationStatus
+	switch command {
+	case "up":
+		result, err = migrationDAO.Up(dryRun)
+	case "down":
+		result, err = migrationDAO.Down(n, dryRun)
+	case "mark-applied":
+		result, err = migrationDAO.MarkApplied(name, dryRun)
+	default:
+		result, err = migrationDAO.Status()
+	}
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return printSQL(result)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for _, item := range result {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printSQL prints the migrations SQL, the mark-applied dry run has only the migration names
+func printSQL(migrations []types.MigrationStatus) error {
+	for _, migration := range migrations {
+		if _, err := fmt.Printf("-- %s\n%s\n", migration.Name, migration.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
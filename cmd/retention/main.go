@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/basicrum/front_basicrum_go/config"
+	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const usage = `Usage: retention <command>
+
+Applies the retention of BRUM_RETENTION_DAYS and BRUM_RETENTION_HOSTNAMES to the tables.
+The database is configured with the server environment variables.
+
+Commands:
+  report  prints the partitions with the expired rows as NDJSON without changing the tables (dry run)
+  apply   modifies the TTL of the tables, the server applies it on startup unless BRUM_DATABASE_AUTO_MIGRATE=false
+`
+
+func main() {
+	if len(os.Args) != 2 || (os.Args[1] != "report" && os.Args[1] != "apply") {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(os.Args[1]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(command string) error {
+	sConf, err := config.GetStartupConfig()
+	if err != nil {
+		return err
+	}
+	retention, err := types.ParseRetention(sConf.Retention.Days, sConf.Retention.Hostnames)
+	if err != nil {
+		return err
+	}
+	database, err := dao.FromConfig(sConf)
+	if err != nil {
+		return err
+	}
+	conn, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	// the command reads the existing hostname tables, so it does not migrate the tables of the new hostnames
+	daoService := database.DAO(conn, nil)
+	defer daoService.Close()
+
+	if command == "apply" {
+		return daoService.ApplyRetention(retention)
+	}
+	partitions, err := daoService.RetentionReport(retention)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for _, partition := range partitions {
+		if err := encoder.Encode(partition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/basicrum/front_basicrum_go/config"
+	"github.com/basicrum/front_basicrum_go/dao"
+)
+
+const usage = `Usage: rollup <command> [flags]
+
+Manages the hourly rollup of the page views.
+The database is configured with the server environment variables.
+
+Commands:
+  apply     creates the materialized view when BRUM_ROLLUP_ENABLED=true and drops it otherwise,
+            the server applies it on startup unless BRUM_DATABASE_AUTO_MIGRATE=false
+  backfill  replaces the hourly rollup rows of the period with the aggregated events,
+            the time is RFC3339 (2024-01-02T15:00:00Z) or date (2024-01-02) in UTC and it is truncated to hour
+
+Flags:
+`
+
+func main() {
+	flags := flag.NewFlagSet("rollup", flag.ExitOnError)
+	from := flags.String("from", "", "start of the backfilled period, inclusive")
+	to := flags.String("to", "", "end of the backfilled period, exclusive, the current hour when empty")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 || (os.Args[1] != "backfill" && os.Args[1] != "apply") {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	if err := run(os.Args[1], *from, *to); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(command, fromValue, toValue string) error {
+	var from, to time.Time
+	if command == "backfill" {
+		var err error
+		if from, err = parseTime(fromValue); err != nil {
+			return err
+		}
+		currentHour := time.Now().Truncate(time.Hour)
+		to = currentHour
+		if toValue != "" {
+			if to, err = parseTime(toValue); err != nil {
+				return err
+			}
+		}
+		// the materialized view keeps adding the rows of the current hour, so the backfill would count them twice
+		if to.After(currentHour) {
+			return fmt.Errorf("backfill period to[%v] must end before the current hour[%v]", to, currentHour)
+		}
+	}
+
+	sConf, err := config.GetStartupConfig()
+	if err != nil {
+		return err
+	}
+	database, err := dao.FromConfig(sConf)
+	if err != nil {
+		return err
+	}
+	conn, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	// the command reads the existing hostname tables, so it does not migrate the tables of the new hostnames
+	daoService := database.DAO(conn, nil)
+	defer daoService.Close()
+
+	if command == "apply" {
+		return daoService.EnableRollup(sConf.Rollup.Enabled)
+	}
+	return daoService.BackfillRollup(from, to)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time[%v] expected RFC3339 or date", value)
+	}
+	return t, nil
+}
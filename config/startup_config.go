@@ -23,6 +23,10 @@ type StartupConfig struct {
 		SSLLetsEncrypt struct {
 			Domain string `envconfig:"BRUM_SERVER_SSL_LETS_ENCRYPT_DOMAIN"`
 		}
+		TrustedProxies     []string `envconfig:"BRUM_SERVER_TRUSTED_PROXIES"`
+		ClientIPHeader     string   `envconfig:"BRUM_SERVER_CLIENT_IP_HEADER"`
+		QueueSize          int      `envconfig:"BRUM_SERVER_QUEUE_SIZE" default:"10000"`
+		QueueTimeoutMillis uint32   `envconfig:"BRUM_SERVER_QUEUE_TIMEOUT_MILLIS" default:"100"`
 	}
 	Subscription struct {
 		Enabled bool `envconfig:"BRUM_SUBSCRIPTION_ENABLED" default:"false"`
@@ -31,12 +35,66 @@ type StartupConfig struct {
 		Token string `envconfig:"BRUM_PRIVATE_API_TOKEN"`
 	}
 	Database struct {
-		Host         string `required:"true" envconfig:"BRUM_DATABASE_HOST"`
-		Port         int16  `required:"true" envconfig:"BRUM_DATABASE_PORT" default:"9000"`
-		Username     string `required:"true" envconfig:"BRUM_DATABASE_USERNAME" default:"default"`
-		Password     string `required:"true" envconfig:"BRUM_DATABASE_PASSWORD"`
-		DatabaseName string `required:"true" envconfig:"BRUM_DATABASE_NAME" default:"default"`
-		TablePrefix  string `envconfig:"BRUM_DATABASE_TABLE_PREFIX"`
+		Host               string `required:"true" envconfig:"BRUM_DATABASE_HOST"`
+		Port               int16  `required:"true" envconfig:"BRUM_DATABASE_PORT" default:"9000"`
+		Username           string `required:"true" envconfig:"BRUM_DATABASE_USERNAME" default:"default"`
+		Password           string `required:"true" envconfig:"BRUM_DATABASE_PASSWORD"`
+		DatabaseName       string `required:"true" envconfig:"BRUM_DATABASE_NAME" default:"default"`
+		TablePrefix        string `envconfig:"BRUM_DATABASE_TABLE_PREFIX"`
+		AutoMigrate        bool   `envconfig:"BRUM_DATABASE_AUTO_MIGRATE" default:"true"`
+		Cluster            string `envconfig:"BRUM_DATABASE_CLUSTER"`
+		ConnOpenStrategy   string `envconfig:"BRUM_DATABASE_CONN_OPEN_STRATEGY" default:"in_order"`
+		Protocol           string `envconfig:"BRUM_DATABASE_PROTOCOL" default:"native"`
+		NativePort         int16  `envconfig:"BRUM_DATABASE_NATIVE_PORT" default:"9000"`
+		Compression        string `envconfig:"BRUM_DATABASE_COMPRESSION" default:"none"`
+		TLS                bool   `envconfig:"BRUM_DATABASE_TLS" default:"false"`
+		TLSCAFile          string `envconfig:"BRUM_DATABASE_TLS_CA_FILE"`
+		TLSCertFile        string `envconfig:"BRUM_DATABASE_TLS_CERT_FILE"`
+		TLSKeyFile         string `envconfig:"BRUM_DATABASE_TLS_KEY_FILE"`
+		TLSSkipVerify      bool   `envconfig:"BRUM_DATABASE_TLS_SKIP_VERIFY" default:"false"`
+		DialTimeoutSeconds uint32 `envconfig:"BRUM_DATABASE_DIAL_TIMEOUT_SECONDS" default:"30"`
+		ReadTimeoutSeconds uint32 `envconfig:"BRUM_DATABASE_READ_TIMEOUT_SECONDS" default:"300"`
+		MaxOpenConns       int    `envconfig:"BRUM_DATABASE_MAX_OPEN_CONNS" default:"10"`
+		MaxIdleConns       int    `envconfig:"BRUM_DATABASE_MAX_IDLE_CONNS" default:"5"`
+		AsyncInsertWait    bool   `envconfig:"BRUM_DATABASE_ASYNC_INSERT_WAIT" default:"false"`
+	}
+	Persistance struct {
+		DatabaseStrategy string `envconfig:"BRUM_PERSISTANCE_DATABASE_STRATEGY" default:"all_in_one_db"`
+		TableStrategy    string `envconfig:"BRUM_PERSISTANCE_TABLE_STRATEGY" default:"all_in_one_table"`
+	}
+	UserAgent struct {
+		RegexesPath          string `envconfig:"BRUM_USER_AGENT_REGEXES_PATH"`
+		RegexesReloadSeconds uint32 `envconfig:"BRUM_USER_AGENT_REGEXES_RELOAD_SECONDS" default:"0"`
+	}
+	Cache struct {
+		UserAgentSize       int    `envconfig:"BRUM_CACHE_USER_AGENT_SIZE" default:"10000"`
+		UserAgentTTLSeconds uint32 `envconfig:"BRUM_CACHE_USER_AGENT_TTL_SECONDS" default:"86400"`
+		GeoIPSize           int    `envconfig:"BRUM_CACHE_GEOIP_SIZE" default:"10000"`
+		GeoIPTTLSeconds     uint32 `envconfig:"BRUM_CACHE_GEOIP_TTL_SECONDS" default:"3600"`
+	}
+	CustomData struct {
+		Allowlist      string `envconfig:"BRUM_CUSTOM_DATA_ALLOWLIST"`
+		MaxNames       int    `envconfig:"BRUM_CUSTOM_DATA_MAX_NAMES" default:"50"`
+		MaxValueLength int    `envconfig:"BRUM_CUSTOM_DATA_MAX_VALUE_LENGTH" default:"256"`
+	}
+	ExtraParams struct {
+		Enabled  bool   `envconfig:"BRUM_EXTRA_PARAMS_ENABLED" default:"false"`
+		Denylist string `envconfig:"BRUM_EXTRA_PARAMS_DENYLIST" default:"h.cr"`
+		MaxSize  int    `envconfig:"BRUM_EXTRA_PARAMS_MAX_SIZE" default:"4096"`
+	}
+	DeadLetter struct {
+		Storage          string `envconfig:"BRUM_DEAD_LETTER_STORAGE" default:"file"`
+		Path             string `envconfig:"BRUM_DEAD_LETTER_PATH" default:"dead_letter/dead_letter.ndjson"`
+		RetryAttempts    int    `envconfig:"BRUM_DEAD_LETTER_RETRY_ATTEMPTS" default:"3"`
+		RetryDelayMillis uint32 `envconfig:"BRUM_DEAD_LETTER_RETRY_DELAY_MILLIS" default:"100"`
+		RetryQueueSize   int    `envconfig:"BRUM_DEAD_LETTER_RETRY_QUEUE_SIZE" default:"1000"`
+	}
+	Retention struct {
+		Days      int    `envconfig:"BRUM_RETENTION_DAYS" default:"0"`
+		Hostnames string `envconfig:"BRUM_RETENTION_HOSTNAMES"`
+	}
+	Rollup struct {
+		Enabled bool `envconfig:"BRUM_ROLLUP_ENABLED" default:"false"`
 	}
 	Backup struct {
 		Enabled          bool   `envconfig:"BRUM_BACKUP_ENABLED" default:"false"`
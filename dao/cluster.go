@@ -0,0 +1,126 @@
+package dao
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// localTableSuffix is the suffix of the replicated tables on the cluster nodes
+	// The distributed tables have the table names used without cluster
+	localTableSuffix   = "_local"
+	migrationSplit     = "--migration:split"
+	defaultShardingKey = "rand()"
+	// sessionShardingKey distributes the rows without session randomly, otherwise they would be on the same shard
+	sessionShardingKey = "if(session_id = toFixedString('', 43), rand(), cityHash64(session_id))"
+)
+
+// clusterShardingKeys distribute the rows of the table between the shards
+// The events of the session are on the same shard so the sessions materialized view aggregates them together
+// The sessions table contains only the rows with session
+// nolint: gochecknoglobals
+var clusterShardingKeys = map[string]string{
+	baseTableName:           sessionShardingKey,
+	baseXhrTableName:        sessionShardingKey,
+	baseErrorsTableName:     sessionShardingKey,
+	baseSessionsTableName:   "cityHash64(session_id)",
+	baseHostsTableName:      "cityHash64(hostname)",
+	baseOwnerHostsTableName: "cityHash64(hostname)",
+}
+
+// nolint: gochecknoglobals
+var (
+	createTableRE = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\S+) \(`)
+	engineRE      = regexp.MustCompile(`ENGINE = (\w*MergeTree)(\(([^)]*)\))?`)
+	alterTableRE  = regexp.MustCompile(`^ALTER TABLE (\S+) `)
+	dropTableRE   = regexp.MustCompile(`^DROP TABLE IF EXISTS (\S+)$`)
+	dropViewRE    = regexp.MustCompile(`^DROP VIEW IF EXISTS (\S+)$`)
+	createMVRE    = regexp.MustCompile(`^CREATE MATERIALIZED VIEW IF NOT EXISTS (\S+) TO (\S+) AS`)
+	fromTableRE   = regexp.MustCompile(`(?m)^FROM (\S+)$`)
+	createViewRE  = regexp.MustCompile(`^CREATE VIEW IF NOT EXISTS (\S+) AS`)
+)
+
+// clusterMigration converts the migration statements with the table prefix into the cluster statements
+// The tables are created as replicated local tables with the distributed tables, all statements are executed ON CLUSTER
+// The materialized views read and write the local tables because they are triggered by the inserts on every node
+func clusterMigration(migration, cluster, prefix string) (string, error) {
+	onCluster := clusterClause(cluster)
+	var result []string
+	for _, statement := range strings.Split(migration, migrationSplit) {
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+		switch {
+		case createTableRE.MatchString(statement):
+			table := createTableRE.FindStringSubmatch(statement)[1]
+			local := createTableRE.ReplaceAllString(statement, "CREATE TABLE IF NOT EXISTS ${1}"+localTableSuffix+onCluster+" (")
+			local = engineRE.ReplaceAllString(local, "ENGINE = Replicated${1}(${3})")
+			result = append(result, local, fmt.Sprintf(
+				"CREATE TABLE IF NOT EXISTS %[1]s%[2]s AS %[1]s%[3]s ENGINE = Distributed('%[4]s', currentDatabase(), '%[1]s%[3]s', %[5]s)",
+				table,
+				onCluster,
+				localTableSuffix,
+				cluster,
+				shardingKey(strings.TrimPrefix(table, prefix)),
+			))
+		case alterTableRE.MatchString(statement):
+			result = append(result,
+				alterTableRE.ReplaceAllString(statement, "ALTER TABLE ${1}"+localTableSuffix+onCluster+" "),
+				alterTableRE.ReplaceAllString(statement, "ALTER TABLE ${1}"+onCluster+" "),
+			)
+		case dropTableRE.MatchString(statement):
+			result = append(result,
+				dropTableRE.ReplaceAllString(statement, "DROP TABLE IF EXISTS ${1}"+onCluster+" SYNC"),
+				dropTableRE.ReplaceAllString(statement, "DROP TABLE IF EXISTS ${1}"+localTableSuffix+onCluster+" SYNC"),
+			)
+		case dropViewRE.MatchString(statement):
+			result = append(result, dropViewRE.ReplaceAllString(statement, "DROP VIEW IF EXISTS ${1}"+onCluster+" SYNC"))
+		case createMVRE.MatchString(statement):
+			view := createMVRE.ReplaceAllString(statement, "CREATE MATERIALIZED VIEW IF NOT EXISTS ${1}"+onCluster+" TO ${2}"+localTableSuffix+" AS")
+			result = append(result, fromTableRE.ReplaceAllString(view, "FROM ${1}"+localTableSuffix))
+		case createViewRE.MatchString(statement):
+			result = append(result, createViewRE.ReplaceAllString(statement, "CREATE VIEW IF NOT EXISTS ${1}"+onCluster+" AS"))
+		default:
+			return "", fmt.Errorf("unsupported cluster migration statement[%v]", statement)
+		}
+	}
+	return strings.Join(result, "\n"+migrationSplit+"\n"), nil
+}
+
+func shardingKey(table string) string {
+	if key, ok := clusterShardingKeys[table]; ok {
+		return key
+	}
+	return defaultShardingKey
+}
+
+// clusterClause returns the ON CLUSTER clause, it is empty without cluster
+func clusterClause(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+	return fmt.Sprintf(" ON CLUSTER '%s'", cluster)
+}
+
+// onCluster returns the ON CLUSTER clause of the DAO statements
+func (p *DAO) onCluster() string {
+	return clusterClause(p.cluster)
+}
+
+// localTable returns the tenant table modified by ALTER and DELETE statements, it is the replicated table on the cluster
+func (p *DAO) localTable(t tenant, name string) string {
+	if p.cluster == "" {
+		return t.table(name)
+	}
+	return t.table(name) + localTableSuffix
+}
+
+// localName returns the local table name without database, it filters the system tables
+func (p *DAO) localName(t tenant, name string) string {
+	if p.cluster == "" {
+		return t.prefix + name
+	}
+	return t.prefix + name + localTableSuffix
+}
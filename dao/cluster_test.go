@@ -0,0 +1,116 @@
+package dao
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/basicrum/front_basicrum_go/templatemigrations"
+)
+
+func Test_clusterMigration(t *testing.T) {
+	tests := []struct {
+		name      string
+		migration string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "create table",
+			migration: `CREATE TABLE IF NOT EXISTS p_webperf_rum_events (
+    hostname LowCardinality(String)
+)
+ENGINE = MergeTree()
+ORDER BY hostname`,
+			want: `CREATE TABLE IF NOT EXISTS p_webperf_rum_events_local ON CLUSTER 'c' (
+    hostname LowCardinality(String)
+)
+ENGINE = ReplicatedMergeTree()
+ORDER BY hostname
+--migration:split
+CREATE TABLE IF NOT EXISTS p_webperf_rum_events ON CLUSTER 'c' AS p_webperf_rum_events_local ` +
+				`ENGINE = Distributed('c', currentDatabase(), 'p_webperf_rum_events_local', if(session_id = toFixedString('', 43), rand(), cityHash64(session_id)))`,
+		},
+		{
+			name: "create table with engine parameters",
+			migration: `CREATE TABLE IF NOT EXISTS p_webperf_rum_dead_letters (
+    id String
+)
+ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY id`,
+			want: `CREATE TABLE IF NOT EXISTS p_webperf_rum_dead_letters_local ON CLUSTER 'c' (
+    id String
+)
+ENGINE = ReplicatedReplacingMergeTree(updated_at)
+ORDER BY id
+--migration:split
+CREATE TABLE IF NOT EXISTS p_webperf_rum_dead_letters ON CLUSTER 'c' AS p_webperf_rum_dead_letters_local ` +
+				`ENGINE = Distributed('c', currentDatabase(), 'p_webperf_rum_dead_letters_local', rand())`,
+		},
+		{
+			name:      "alter table",
+			migration: "ALTER TABLE p_webperf_rum_events ADD COLUMN a UInt8",
+			want: `ALTER TABLE p_webperf_rum_events_local ON CLUSTER 'c' ADD COLUMN a UInt8
+--migration:split
+ALTER TABLE p_webperf_rum_events ON CLUSTER 'c' ADD COLUMN a UInt8`,
+		},
+		{
+			name: "materialized view and drop",
+			migration: `DROP VIEW IF EXISTS p_mv
+--migration:split
+DROP TABLE IF EXISTS p_webperf_rum_sessions
+--migration:split
+CREATE MATERIALIZED VIEW IF NOT EXISTS p_mv TO p_webperf_rum_sessions AS
+SELECT hostname
+FROM p_webperf_rum_events`,
+			want: `DROP VIEW IF EXISTS p_mv ON CLUSTER 'c' SYNC
+--migration:split
+DROP TABLE IF EXISTS p_webperf_rum_sessions ON CLUSTER 'c' SYNC
+--migration:split
+DROP TABLE IF EXISTS p_webperf_rum_sessions_local ON CLUSTER 'c' SYNC
+--migration:split
+CREATE MATERIALIZED VIEW IF NOT EXISTS p_mv ON CLUSTER 'c' TO p_webperf_rum_sessions_local AS
+SELECT hostname
+FROM p_webperf_rum_events_local`,
+		},
+		{
+			name:      "unsupported statement",
+			migration: "RENAME TABLE p_a TO p_b",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := clusterMigration(tt.migration, "c", "p_")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_clusterMigration_templates(t *testing.T) {
+	files, err := fs.Glob(templatemigrations.SQLMigrations, "*.sql")
+	require.NoError(t, err)
+	for _, file := range files {
+		content, err := fs.ReadFile(templatemigrations.SQLMigrations, file)
+		require.NoError(t, err)
+		migration := strings.ReplaceAll(string(content), tablePrefixPlaceholder, "p_")
+		got, err := clusterMigration(migration, "c", "p_")
+		require.NoError(t, err, file)
+		require.NotContains(t, got, "ENGINE = MergeTree", file)
+	}
+}
+
+func Test_Server(t *testing.T) {
+	require.Equal(t, []string{"localhost:9000"}, Server("localhost", 9000, "default").addrs)
+	require.Equal(t,
+		[]string{"ch1:9000", "ch2:9001", "10.0.0.3:9000"},
+		Server("ch1, ch2:9001,10.0.0.3", 9000, "default").addrs,
+	)
+}
@@ -1,23 +1,242 @@
 package dao
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/uptrace/go-clickhouse/ch"
 )
 
+const (
+	defaultDialTimeout  = 30 * time.Second
+	defaultReadTimeout  = 5 * time.Minute
+	defaultMaxOpenConns = 10
+	defaultMaxIdleConns = 5
+)
+
+// ConnectionOption configures the clickhouse connection
+type ConnectionOption func(*connection) error
+
+// connection contains the settings shared by the DAO and the migrator connections
+type connection struct {
+	openStrategy clickhouse.ConnOpenStrategy
+	protocol     clickhouse.Protocol
+	// nativePort is used by the migrator with the http protocol, the migrator supports only the native protocol
+	nativePort   int16
+	compression  clickhouse.CompressionMethod
+	tls          *tls.Config
+	dialTimeout  time.Duration
+	readTimeout  time.Duration
+	maxOpenConns int
+	maxIdleConns int
+}
+
+// Connection creates the connection settings of the DAO and the migrator
+// nolint: revive
+func Connection(options ...ConnectionOption) (*connection, error) {
+	result := &connection{
+		openStrategy: clickhouse.ConnOpenInOrder,
+		protocol:     clickhouse.Native,
+		compression:  clickhouse.CompressionNone,
+		dialTimeout:  defaultDialTimeout,
+		readTimeout:  defaultReadTimeout,
+		maxOpenConns: defaultMaxOpenConns,
+		maxIdleConns: defaultMaxIdleConns,
+	}
+	for _, o := range options {
+		if err := o(result); err != nil {
+			return nil, err
+		}
+	}
+	if result.maxIdleConns > result.maxOpenConns {
+		return nil, fmt.Errorf("max idle connections[%v] must not exceed max open connections[%v]", result.maxIdleConns, result.maxOpenConns)
+	}
+	return result, nil
+}
+
+// WithConnOpenStrategy selects the node of the new connections
+// in_order uses the first available node (failover) and round_robin balances the connections between the nodes
+func WithConnOpenStrategy(strategy string) ConnectionOption {
+	return func(c *connection) error {
+		switch strategy {
+		case "", "in_order":
+			c.openStrategy = clickhouse.ConnOpenInOrder
+		case "round_robin":
+			c.openStrategy = clickhouse.ConnOpenRoundRobin
+		default:
+			return fmt.Errorf("unsupported connection open strategy[%v]", strategy)
+		}
+		return nil
+	}
+}
+
+// WithProtocol selects the native or the http protocol of the DAO
+// The migrator supports only the native protocol, it connects to the native port when the http protocol is used
+func WithProtocol(protocol string, nativePort int16) ConnectionOption {
+	return func(c *connection) error {
+		switch protocol {
+		case "", "native":
+			c.protocol = clickhouse.Native
+		case "http":
+			if nativePort <= 0 {
+				return fmt.Errorf("native port[%v] of the migrator is required with the http protocol", nativePort)
+			}
+			c.protocol = clickhouse.HTTP
+			c.nativePort = nativePort
+		default:
+			return fmt.Errorf("unsupported connection protocol[%v]", protocol)
+		}
+		return nil
+	}
+}
+
+// WithCompression selects the compression of the transferred data: none, lz4 or zstd
+// The migrator supports only lz4, it uses lz4 when zstd is selected
+func WithCompression(method string) ConnectionOption {
+	return func(c *connection) error {
+		switch method {
+		case "", "none":
+			c.compression = clickhouse.CompressionNone
+		case "lz4":
+			c.compression = clickhouse.CompressionLZ4
+		case "zstd":
+			c.compression = clickhouse.CompressionZSTD
+		default:
+			return fmt.Errorf("unsupported connection compression[%v]", method)
+		}
+		return nil
+	}
+}
+
+// WithTLS enables the secure connection
+// The empty caFile uses the system certificate pool, the certFile and the keyFile are the client certificate
+// The skipVerify accepts any server certificate, it should be used only in development
+func WithTLS(enabled bool, caFile, certFile, keyFile string, skipVerify bool) ConnectionOption {
+	return func(c *connection) error {
+		if !enabled {
+			if caFile != "" || certFile != "" || keyFile != "" || skipVerify {
+				return fmt.Errorf("tls settings require enabled tls")
+			}
+			c.tls = nil
+			return nil
+		}
+		result := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// nolint: gosec
+			InsecureSkipVerify: skipVerify,
+		}
+		if caFile != "" {
+			ca, err := os.ReadFile(caFile)
+			if err != nil {
+				return fmt.Errorf("cannot read tls ca file[%v] err[%w]", caFile, err)
+			}
+			result.RootCAs = x509.NewCertPool()
+			if !result.RootCAs.AppendCertsFromPEM(ca) {
+				return fmt.Errorf("tls ca file[%v] does not contain PEM certificates", caFile)
+			}
+		}
+		if certFile != "" || keyFile != "" {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return fmt.Errorf("cannot load tls client cert file[%v] key file[%v] err[%w]", certFile, keyFile, err)
+			}
+			result.Certificates = []tls.Certificate{cert}
+		}
+		c.tls = result
+		return nil
+	}
+}
+
+// WithTimeouts configures the timeouts of the dial and the socket reads, the zero values keep the defaults
+func WithTimeouts(dial, read time.Duration) ConnectionOption {
+	return func(c *connection) error {
+		if dial > 0 {
+			c.dialTimeout = dial
+		}
+		if read > 0 {
+			c.readTimeout = read
+		}
+		return nil
+	}
+}
+
+// WithPool configures the maximum open and idle connections, the zero values keep the defaults
+func WithPool(maxOpenConns, maxIdleConns int) ConnectionOption {
+	return func(c *connection) error {
+		if maxOpenConns < 0 || maxIdleConns < 0 {
+			return fmt.Errorf("max open connections[%v] and max idle connections[%v] must not be negative", maxOpenConns, maxIdleConns)
+		}
+		if maxOpenConns > 0 {
+			c.maxOpenConns = maxOpenConns
+		}
+		if maxIdleConns > 0 {
+			c.maxIdleConns = maxIdleConns
+		}
+		return nil
+	}
+}
+
+// migratorAddr returns the native address of the node, the http port is replaced by the native port
+func (c *connection) migratorAddr(addr string) string {
+	if c.protocol != clickhouse.HTTP {
+		return addr
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.JoinHostPort(host, strconv.FormatInt(int64(c.nativePort), 10))
+}
+
+// migratorOptions applies the connection settings to the migrator connection
+func (c *connection) migratorOptions() []ch.Option {
+	return []ch.Option{
+		ch.WithTLSConfig(c.tls),
+		ch.WithCompression(c.compression != clickhouse.CompressionNone),
+		ch.WithDialTimeout(c.dialTimeout),
+		ch.WithReadTimeout(c.readTimeout),
+		ch.WithPoolSize(c.maxOpenConns),
+		withMaxIdleConns(c.maxIdleConns),
+	}
+}
+
+// withMaxIdleConns limits the idle migrator connections, ch.WithPoolSize sets the limit to the pool size
+// The option must follow ch.WithPoolSize, the options are applied before the pool is created
+func withMaxIdleConns(maxIdleConns int) ch.Option {
+	return func(db *ch.DB) {
+		db.Config().MaxIdleConns = maxIdleConns
+	}
+}
+
 // New Clickhouse connection
 // nolint: revive
-func NewConnection(s server, a auth) (driver.Conn, error) {
-	return clickhouse.Open(&clickhouse.Options{
-		Addr: []string{s.addr()},
+func NewConnection(s server, a auth, c *connection) (driver.Conn, error) {
+	clickhouseOptions := &clickhouse.Options{
+		Addr:     s.addrs,
+		Protocol: c.protocol,
 		Auth: clickhouse.Auth{
 			Database: s.db,
 			Username: a.user,
 			Password: a.pwd,
 		},
-		Debug:           false,
-		ConnMaxLifetime: time.Hour,
-	})
+		TLS:              c.tls,
+		Debug:            false,
+		DialTimeout:      c.dialTimeout,
+		ReadTimeout:      c.readTimeout,
+		MaxOpenConns:     c.maxOpenConns,
+		MaxIdleConns:     c.maxIdleConns,
+		ConnMaxLifetime:  time.Hour,
+		ConnOpenStrategy: c.openStrategy,
+	}
+	if c.compression != clickhouse.CompressionNone {
+		clickhouseOptions.Compression = &clickhouse.Compression{Method: c.compression}
+	}
+	return clickhouse.Open(clickhouseOptions)
 }
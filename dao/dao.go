@@ -3,9 +3,12 @@ package dao
 //go:generate mockgen -source=${GOFILE} -destination=mocks/${GOFILE} -package=daomocks
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 
@@ -16,6 +19,9 @@ import (
 const (
 	baseTableName           = "webperf_rum_events"
 	baseHostsTableName      = "webperf_rum_hostnames"
+	baseXhrTableName        = "webperf_rum_xhr"
+	baseErrorsTableName     = "webperf_rum_errors"
+	baseSessionsTableName   = "webperf_rum_sessions"
 	baseOwnerHostsTableName = "webperf_rum_own_hostnames"
 	tablePrefixPlaceholder  = "{prefix}"
 	bufferSize              = 1024
@@ -26,26 +32,82 @@ type IDAO interface {
 	Close() error
 	Save(rumEvent beacon.RumEvent) error
 	SaveHost(event beacon.HostnameEvent) error
+	SaveXhr(event beacon.XhrEvent) error
+	SaveErrors(events []beacon.ErrorEvent) error
+	InsertRows(table string, rows []byte) error
 	InsertOwnerHostname(item types.OwnerHostname) error
 	DeleteOwnerHostname(hostname, username string) error
 	GetSubscriptions() (map[string]*types.SubscriptionWithHostname, error)
 	GetSubscription(id string) (*types.SubscriptionWithHostname, error)
+	SeenHostnames(days int, unregisteredOnly bool) ([]types.SeenHostname, error)
+	Erase(request types.ErasureRequest) error
+	ErasureStatus(request types.ErasureRequest) ([]types.ErasureStatus, error)
+	Columns() (map[string]string, error)
+	Metrics(filter types.MetricsFilter) (*types.Metrics, error)
+}
+
+// InsertError contains the rows which failed to be inserted
+type InsertError struct {
+	// Table is the table name without prefix
+	Table string
+	Rows  []byte
+	Err   error
+}
+
+func (e *InsertError) Error() string {
+	return fmt.Sprintf("clickhouse insert table[%v] failed: %v", e.Table, e.Err)
+}
+
+func (e *InsertError) Unwrap() error {
+	return e.Err
 }
 
 // DAO is data access object for clickhouse database
 type DAO struct {
-	conn   clickhouse.Conn
-	table  string
-	prefix string
+	conn     clickhouse.Conn
+	table    string
+	prefix   string
+	cluster  string
+	strategy Strategy
+	migrator ITenantMigrator
+	// asyncInsertWait waits until the async insert is written, so the server side insert errors are returned
+	asyncInsertWait bool
+
+	// tenantsMu guards the ready tenants and the settings applied to the new tenants
+	// The settings are nil until they are applied to the default tenant
+	tenantsMu     sync.Mutex
+	tenants       map[tenant]struct{}
+	rollupEnabled *bool
+	retention     *types.Retention
+
+	// registeredMu guards the cached owner hostnames, the nil cache is reloaded on the next use
+	registeredMu sync.Mutex
+	registered   map[string]struct{}
+	registeredAt time.Time
 }
 
 // New creates persistance service
 // nolint: revive
-func New(conn clickhouse.Conn, opts *opts) *DAO {
-	return &DAO{
-		conn:   conn,
-		table:  fullTableName(opts),
-		prefix: opts.prefix,
+func New(conn clickhouse.Conn, opts *opts, options ...func(*DAO)) *DAO {
+	result := &DAO{
+		conn:     conn,
+		table:    fullTableName(opts),
+		prefix:   opts.prefix,
+		cluster:  opts.cluster,
+		strategy: Strategy{database: AllInOneDB, table: AllInOneTable},
+		tenants:  map[tenant]struct{}{},
+	}
+	for _, o := range options {
+		o(result)
+	}
+	return result
+}
+
+// WithAsyncInsertWait waits for the async inserts. Without waiting only the connection errors are returned,
+// the rows rejected by the server are not stored as dead letters
+func WithAsyncInsertWait(wait bool) func(*DAO) {
+	return func(p *DAO) {
+		p.asyncInsertWait = wait
 	}
 }
 
@@ -60,21 +122,11 @@ func (p *DAO) Close() error {
 
 // Save stores data into table in clickhouse database
 func (p *DAO) Save(rumEvent beacon.RumEvent) error {
-	jsonValue, err := json.Marshal(rumEvent)
+	jsonValue, err := eventRows(rumEvent)
 	if err != nil {
-		return fmt.Errorf("json[%+v] parsing error: %w", rumEvent, err)
-	}
-	data := string(jsonValue)
-	query := fmt.Sprintf(
-		"INSERT INTO %s SETTINGS input_format_skip_unknown_fields = true FORMAT JSONEachRow %s",
-		p.table,
-		data,
-	)
-	err = p.conn.AsyncInsert(context.Background(), query, false)
-	if err != nil {
-		return fmt.Errorf("clickhouse insert failed: %w", err)
+		return err
 	}
-	return nil
+	return p.insertHostname(rumEvent.Hostname, baseTableName, jsonValue)
 }
 
 // SaveHost stores hostname data into table in clickhouse database
@@ -83,15 +135,78 @@ func (p *DAO) SaveHost(event beacon.HostnameEvent) error {
 	if err != nil {
 		return err
 	}
+	return p.insert(p.defaultTenant(), baseHostsTableName, data)
+}
+
+// SaveXhr stores xhr request data into table in clickhouse database
+func (p *DAO) SaveXhr(event beacon.XhrEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.insertHostname(event.Hostname, baseXhrTableName, data)
+}
+
+// SaveErrors stores JavaScript errors into table in clickhouse database
+func (p *DAO) SaveErrors(events []beacon.ErrorEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	data, err := errorRows(events)
+	if err != nil {
+		return err
+	}
+	return p.insertHostname(events[0].Hostname, baseErrorsTableName, data)
+}
+
+func eventRows(rumEvent beacon.RumEvent) ([]byte, error) {
+	jsonValue, err := json.Marshal(rumEvent)
+	if err != nil {
+		return nil, fmt.Errorf("json[%+v] parsing error: %w", rumEvent, err)
+	}
+	return jsonValue, nil
+}
+
+func errorRows(events []beacon.ErrorEvent) ([]byte, error) {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+	return data.Bytes(), nil
+}
+
+// InsertRows inserts JSONEachRow rows into table without prefix, it is used to resubmit the dead letters
+// The rows of the hostname tables are routed by the hostname of the rows
+func (p *DAO) InsertRows(table string, rows []byte) error {
+	if _, ok := tenantTables[table]; ok {
+		return p.insertHostname(rowsHostname(rows), table, rows)
+	}
+	return p.insert(p.defaultTenant(), table, rows)
+}
+
+// insertHostname inserts JSONEachRow rows into the hostname tenant table without prefix
+// The failed tenant setup is returned as InsertError so the rows are not lost
+func (p *DAO) insertHostname(hostname, table string, rows []byte) error {
+	t, err := p.hostnameTenant(hostname)
+	if err != nil {
+		return &InsertError{Table: table, Rows: rows, Err: err}
+	}
+	return p.insert(t, table, rows)
+}
+
+// insert inserts JSONEachRow rows into the tenant table without prefix
+func (p *DAO) insert(t tenant, table string, rows []byte) error {
 	query := fmt.Sprintf(
-		"INSERT INTO %s%s SETTINGS input_format_skip_unknown_fields = true FORMAT JSONEachRow %s",
-		p.prefix,
-		baseHostsTableName,
-		data,
+		"INSERT INTO %s SETTINGS input_format_skip_unknown_fields = true FORMAT JSONEachRow %s",
+		t.table(table),
+		rows,
 	)
-	err = p.conn.AsyncInsert(context.Background(), query, false)
+	err := p.conn.AsyncInsert(context.Background(), query, p.asyncInsertWait)
 	if err != nil {
-		return fmt.Errorf("clickhouse insert failed: %w", err)
+		return &InsertError{Table: table, Rows: rows, Err: err}
 	}
 	return nil
 }
@@ -103,17 +218,27 @@ func (p *DAO) InsertOwnerHostname(item types.OwnerHostname) error {
 		p.prefix,
 		baseOwnerHostsTableName,
 	)
-	return p.conn.Exec(context.Background(), query, item.Username, item.Hostname, item.Subscription.ID, item.Subscription.ExpiresAt)
+	err := p.conn.Exec(context.Background(), query, item.Username, item.Hostname, item.Subscription.ID, item.Subscription.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	p.addRegistered(item.Hostname)
+	return nil
 }
 
 // DeleteOwnerHostname deletes the hostname
 func (p *DAO) DeleteOwnerHostname(hostname, username string) error {
 	query := fmt.Sprintf(
 		"DELETE FROM %s%s WHERE hostname = ? AND username = ?",
-		p.prefix,
-		baseOwnerHostsTableName,
+		p.localTable(p.defaultTenant(), baseOwnerHostsTableName),
+		p.onCluster(),
 	)
-	return p.conn.Exec(context.Background(), query, hostname, username)
+	if err := p.conn.Exec(context.Background(), query, hostname, username); err != nil {
+		return err
+	}
+	// the hostname can be registered by the other owners
+	p.resetRegistered()
+	return nil
 }
 
 // GetSubscriptions gets all subscriptions
@@ -173,3 +298,91 @@ func (p *DAO) GetSubscription(id string) (*types.SubscriptionWithHostname, error
 
 	return &result, nil
 }
+
+// SeenHostnames gets the seen hostnames with the number of the events in the last days
+// The hostname is registered when it is in the owner hostnames table
+func (p *DAO) SeenHostnames(days int, unregisteredOnly bool) ([]types.SeenHostname, error) {
+	where := ""
+	if unregisteredOnly {
+		where = "WHERE o.hostname = ''"
+	}
+	query := fmt.Sprintf(`
+	SELECT h.hostname, h.last_seen, e.events, o.hostname != '' AS registered
+	FROM (SELECT hostname, max(updated_at) AS last_seen FROM %[1]s%[2]s GROUP BY hostname) AS h
+	LEFT JOIN (SELECT hostname, count() AS events FROM %[1]s%[3]s WHERE event_date > today() - ? GROUP BY hostname) AS e
+		ON e.hostname = h.hostname
+	LEFT JOIN (SELECT DISTINCT hostname FROM %[1]s%[4]s FINAL) AS o
+		ON o.hostname = h.hostname
+	%[5]s
+	ORDER BY h.last_seen DESC
+	`,
+		p.prefix,
+		baseHostsTableName,
+		baseTableName,
+		baseOwnerHostsTableName,
+		where,
+	)
+	rows, err := p.conn.Query(context.Background(), query, days)
+	if err != nil {
+		return nil, fmt.Errorf("get seen hostnames failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []types.SeenHostname
+	for rows.Next() {
+		var item types.SeenHostname
+		if err := rows.Scan(&item.Hostname, &item.LastSeen, &item.Events, &item.Registered); err != nil {
+			return result, fmt.Errorf("get seen hostnames failed: %w", err)
+		}
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil || p.strategy.AllInOne() {
+		return result, err
+	}
+	return result, p.countTenantEvents(result, days)
+}
+
+// countTenantEvents adds the events of the hostnames stored in the existing tenant tables
+func (p *DAO) countTenantEvents(items []types.SeenHostname, days int) error {
+	existing, err := p.existingTenantSet()
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		t := p.strategy.tenant(p.prefix, items[i].Hostname)
+		if _, ok := existing[t]; !ok {
+			continue
+		}
+		query := fmt.Sprintf(
+			"SELECT count() FROM %s WHERE hostname = ? AND event_date > today() - ?",
+			t.table(baseTableName),
+		)
+		var events uint64
+		if err := p.conn.QueryRow(context.Background(), query, items[i].Hostname, days).Scan(&events); err != nil {
+			return fmt.Errorf("get seen hostname[%v] events failed: %w", items[i].Hostname, err)
+		}
+		// the events before the hostname registration are in the default tables
+		items[i].Events += events
+	}
+	return nil
+}
+
+// Columns gets the column types of the events table by column name
+func (p *DAO) Columns() (map[string]string, error) {
+	query := "SELECT name, type FROM system.columns WHERE database = currentDatabase() AND table = ?"
+	rows, err := p.conn.Query(context.Background(), query, p.table)
+	if err != nil {
+		return nil, fmt.Errorf("get columns table[%v] failed: %w", p.table, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var name, columnType string
+		if err := rows.Scan(&name, &columnType); err != nil {
+			return nil, fmt.Errorf("get columns table[%v] failed: %w", p.table, err)
+		}
+		result[name] = columnType
+	}
+	return result, rows.Err()
+}
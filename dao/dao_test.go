@@ -12,7 +12,10 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
-const sleepDuration = 2 * time.Second
+const (
+	sleepDuration                = 2 * time.Second
+	baseSessionsSummaryTableName = "webperf_rum_sessions_summary"
+)
 
 type daoTestSuite struct {
 	suite.Suite
@@ -38,21 +41,26 @@ func (s *daoTestSuite) SetupTest() {
 	daoServer := Server(sConf.Database.Host, sConf.Database.Port, sConf.Database.DatabaseName)
 	daoAuth := Auth(sConf.Database.Username, sConf.Database.Password)
 
+	daoConnection, err := Connection()
+	s.NoError(err)
+
 	conn, err := NewConnection(
 		daoServer,
 		daoAuth,
+		daoConnection,
 	)
 	s.NoError(err)
 
 	s.dao = New(
 		conn,
-		Opts(sConf.Database.TablePrefix),
+		Opts(sConf.Database.TablePrefix, sConf.Database.Cluster),
 	)
 
 	s.migrationDAO = NewMigrationDAO(
 		daoServer,
 		daoAuth,
-		Opts(sConf.Database.TablePrefix),
+		daoConnection,
+		Opts(sConf.Database.TablePrefix, sConf.Database.Cluster),
 	)
 
 	err = s.migrationDAO.Migrate()
@@ -65,6 +73,8 @@ func (s *daoTestSuite) deleteAll() {
 	s.truncateTable(baseTableName)
 	s.truncateTable(baseHostsTableName)
 	s.truncateTable(baseOwnerHostsTableName)
+	s.truncateTable(baseSessionsTableName)
+	s.truncateTable(baseRollupTableName)
 }
 
 func (s *daoTestSuite) TearDownTest() {
@@ -105,6 +115,37 @@ func (s *daoTestSuite) Test_SaveHost() {
 	s.Equal(1, s.countRows(baseHostsTableName))
 }
 
+func (s *daoTestSuite) Test_SeenHostnames() {
+	// given
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	s.NoError(s.dao.SaveHost(beacon.NewHostnameEvent("www.example.com", now)))
+	s.NoError(s.dao.SaveHost(beacon.NewHostnameEvent("host2", "2022-08-27 05:53:00")))
+	s.NoError(s.dao.Save(s.sessionEvent("", now, beacon.EventTypeVisitPage, "https://www.example.com/", 1000)))
+	s.NoError(s.dao.InsertOwnerHostname(types.NewOwnerHostname("test1", "host2", types.NewSubscription(time.Now()))))
+	sleep()
+
+	// when
+	result, err := s.dao.SeenHostnames(30, false)
+
+	// then
+	s.NoError(err)
+	s.Len(result, 2)
+	s.Equal("www.example.com", result[0].Hostname)
+	s.Equal(uint64(1), result[0].Events)
+	s.False(result[0].Registered)
+	s.Equal("host2", result[1].Hostname)
+	s.Equal(uint64(0), result[1].Events)
+	s.True(result[1].Registered)
+
+	// when
+	result, err = s.dao.SeenHostnames(30, true)
+
+	// then
+	s.NoError(err)
+	s.Len(result, 1)
+	s.Equal("www.example.com", result[0].Hostname)
+}
+
 func (s *daoTestSuite) Test_InsertOwnerHostname() {
 	// given
 	ownerHostname := types.NewOwnerHostname(
@@ -234,6 +275,280 @@ func (s *daoTestSuite) Test_GetSubscriptions() {
 	s.Equal(hostname, item.Hostname)
 }
 
+func (s *daoTestSuite) Test_Sessions() {
+	// given
+	sessionID := "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf"
+	events := []beacon.RumEvent{
+		s.sessionEvent(sessionID, "2022-08-27 05:52:55", beacon.EventTypeVisitPage, "https://www.example.com/", 2000),
+		s.sessionEvent(sessionID, "2022-08-27 05:53:30", beacon.EventTypeVisitPage, "https://www.example.com/cart", 3000),
+		s.sessionEvent(sessionID, "2022-08-27 05:54:00", beacon.EventTypeQuitPage, "https://www.example.com/cart", 0),
+		s.sessionEvent("", "2022-08-27 05:55:00", beacon.EventTypeVisitPage, "https://www.example.com/", 5000),
+	}
+
+	// when
+	for _, event := range events {
+		s.NoError(s.dao.Save(event))
+	}
+	// and
+	sleep()
+
+	// then
+	query := fmt.Sprintf(
+		`SELECT page_count, bounce, duration, landing_url, exit_url, worst_largest_contentful_paint
+		FROM %v%v WHERE hostname = 'www.example.com'`,
+		s.dao.prefix,
+		baseSessionsSummaryTableName,
+	)
+	rows, err := s.dao.conn.Query(context.Background(), query)
+	s.NoError(err)
+	defer rows.Close()
+
+	s.True(rows.Next())
+	var pageCount uint64
+	var bounce uint8
+	var duration int64
+	var landingURL, exitURL string
+	var worstLCP *uint16
+	s.NoError(rows.Scan(&pageCount, &bounce, &duration, &landingURL, &exitURL, &worstLCP))
+	s.Equal(uint64(2), pageCount)
+	s.Equal(uint8(0), bounce)
+	s.Equal(int64(65), duration)
+	s.Equal("https://www.example.com/", landingURL)
+	s.Equal("https://www.example.com/cart", exitURL)
+	s.Equal(uint16(3000), *worstLCP)
+	s.False(rows.Next())
+}
+
+func (s *daoTestSuite) Test_BackfillRollup() {
+	// given
+	events := []beacon.RumEvent{
+		s.sessionEvent("", "2022-08-27 05:10:00", beacon.EventTypeVisitPage, "https://www.example.com/product/12", 1000),
+		s.sessionEvent("", "2022-08-27 05:20:00", beacon.EventTypeVisitPage, "https://www.example.com/product/34", 3000),
+		s.sessionEvent("", "2022-08-27 05:30:00", beacon.EventTypeQuitPage, "https://www.example.com/product/34", 0),
+	}
+	for _, event := range events {
+		s.NoError(s.dao.Save(event))
+	}
+	sleep()
+	from := time.Date(2022, 8, 27, 0, 0, 0, 0, time.UTC)
+
+	// when
+	for i := 0; i < 2; i++ {
+		s.NoError(s.dao.BackfillRollup(from, from.Add(48*time.Hour)))
+	}
+
+	// then
+	query := fmt.Sprintf(
+		`SELECT url_group, sum(page_views), quantilesMerge(0.5, 0.75, 0.95)(largest_contentful_paint)
+		FROM %v%v WHERE hostname = 'www.example.com' GROUP BY url_group`,
+		s.dao.prefix,
+		baseRollupTableName,
+	)
+	rows, err := s.dao.conn.Query(context.Background(), query)
+	s.NoError(err)
+	defer rows.Close()
+
+	s.True(rows.Next())
+	var urlGroup string
+	var pageViews uint64
+	var lcp []float64
+	s.NoError(rows.Scan(&urlGroup, &pageViews, &lcp))
+	s.Equal("/product/:id", urlGroup)
+	s.Equal(uint64(2), pageViews)
+	s.Len(lcp, 3)
+	s.False(rows.Next())
+}
+
+func (s *daoTestSuite) Test_Metrics() {
+	// given
+	events := []beacon.RumEvent{
+		s.sessionEvent("", "2022-08-27 05:10:00", beacon.EventTypeVisitPage, "https://www.example.com/product/12", 1000),
+		s.sessionEvent("", "2022-08-27 05:20:00", beacon.EventTypeVisitPage, "https://www.example.com/product/34", 3000),
+		s.sessionEvent("", "2022-08-27 05:30:00", beacon.EventTypeVisitPage, "https://www.example.com/cart", 5000),
+		s.sessionEvent("", "2022-08-27 05:40:00", beacon.EventTypeVisitPage, "https://www.example.com/product/56", 0),
+	}
+	for _, event := range events {
+		s.NoError(s.dao.Save(event))
+	}
+	sleep()
+	filter := types.MetricsFilter{
+		Hostname:   "www.example.com",
+		From:       time.Date(2022, 8, 27, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2022, 8, 28, 0, 0, 0, 0, time.UTC),
+		URLPattern: "*/product/*",
+	}
+
+	// when
+	result, err := s.dao.Metrics(filter)
+
+	// then
+	s.NoError(err)
+	s.Equal(uint64(3), result.PageViews)
+	s.Len(result.Vitals, 6)
+	lcp := result.Vitals[0]
+	s.Equal("lcp", lcp.Name)
+	s.Equal(uint64(2), lcp.Samples)
+	s.NotNil(lcp.P75)
+	s.Equal(0.5, lcp.Good)
+	s.Equal(0.5, lcp.NeedsImprovement)
+	s.Equal(0.0, lcp.Poor)
+	inp := result.Vitals[4]
+	s.Equal("inp", inp.Name)
+	s.Equal(uint64(0), inp.Samples)
+	s.Nil(inp.P75)
+}
+
+func (s *daoTestSuite) Test_EraseSessions() {
+	// given
+	erasedID := "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf"
+	keptID := "5d6c1ce5-3ce6-5b3e-a2fd-6e2b1f2f1091-snt9og"
+	s.NoError(s.dao.Save(s.sessionEvent(erasedID, "2022-08-27 05:52:55", beacon.EventTypeVisitPage, "https://www.example.com/", 2000)))
+	s.NoError(s.dao.Save(s.sessionEvent(keptID, "2022-08-27 05:53:30", beacon.EventTypeVisitPage, "https://www.example.com/", 3000)))
+	sleep()
+
+	request := types.ErasureRequest{SessionIDs: []string{erasedID}}
+
+	// when
+	s.NoError(s.dao.Erase(request))
+
+	// then
+	s.Eventually(func() bool {
+		statuses, err := s.dao.ErasureStatus(request)
+		s.NoError(err)
+		for _, status := range statuses {
+			if status.RemainingRows > 0 || status.PendingMutations > 0 {
+				return false
+			}
+		}
+		return true
+	}, 10*sleepDuration, sleepDuration/4)
+	s.Equal(1, s.countRows(baseTableName))
+	s.Equal(1, s.countRows(baseSessionsTableName))
+}
+
+func (s *daoTestSuite) Test_ErasePages() {
+	// given
+	sessionID := "4c5b0bd4-2bd5-4a2d-91ec-5d1a0e1e0f80-rmr8nf"
+	erased := s.sessionEvent(sessionID, "2022-08-27 05:52:55", beacon.EventTypeVisitPage, "https://www.example.com/", 2000)
+	erased.Columns["page_id"] = "erased01"
+	kept := s.sessionEvent(sessionID, "2022-08-27 05:53:30", beacon.EventTypeVisitPage, "https://www.example.com/about", 3000)
+	kept.Columns["page_id"] = "kept0001"
+	s.NoError(s.dao.Save(erased))
+	s.NoError(s.dao.Save(kept))
+	sleep()
+	request := types.ErasureRequest{PageIDs: []string{"erased01"}}
+
+	// when
+	s.NoError(s.dao.Erase(request))
+
+	// then the page view is deleted and the aggregated session with its landing url is deleted whole
+	s.Eventually(func() bool {
+		statuses, err := s.dao.ErasureStatus(request)
+		s.NoError(err)
+		for _, status := range statuses {
+			if status.RemainingRows > 0 || status.PendingMutations > 0 {
+				return false
+			}
+		}
+		return true
+	}, 10*sleepDuration, sleepDuration/4)
+	s.Equal(1, s.countRows(baseTableName))
+	s.Equal(0, s.countRows(baseSessionsTableName))
+}
+
+func (s *daoTestSuite) Test_MigrationDownUp() {
+	// when
+	planned, err := s.migrationDAO.Down(1, true)
+
+	// then
+	s.NoError(err)
+	s.Len(planned, 1)
+	s.Contains(planned[0].SQL, s.dao.prefix+baseOwnerHostsTableName)
+
+	// when
+	rolledBack, err := s.migrationDAO.Down(1, false)
+
+	// then
+	s.NoError(err)
+	s.Equal(planned[0].Name, rolledBack[0].Name)
+	statuses, err := s.migrationDAO.Status()
+	s.NoError(err)
+	s.False(statuses[len(statuses)-1].Applied)
+
+	// when
+	applied, err := s.migrationDAO.Up(false)
+
+	// then
+	s.NoError(err)
+	s.Len(applied, 1)
+	s.Equal(planned[0].Name, applied[0].Name)
+	statuses, err = s.migrationDAO.Status()
+	s.NoError(err)
+	s.True(statuses[len(statuses)-1].Applied)
+}
+
+func (s *daoTestSuite) Test_TablePerHostname() {
+	// given
+	strategy, err := NewStrategy(string(AllInOneDB), string(TablePerHostname), "default")
+	s.NoError(err)
+	tenantDAO := New(s.dao.conn, Opts(s.dao.prefix, s.dao.cluster), WithStrategy(strategy, s.migrationDAO))
+	tenantTable := tenantName("www.example.com") + "_" + baseTableName
+	// and the unregistered hostname is stored in the default tables
+	err = tenantDAO.Save(s.sessionEvent("", "2022-08-27 05:52:55", beacon.EventTypeVisitPage, "https://www.example.com/", 2000))
+	s.NoError(err)
+	// and
+	err = tenantDAO.InsertOwnerHostname(types.NewOwnerHostname("user", "www.example.com", types.NewSubscription(time.Now())))
+	s.NoError(err)
+	_, err = tenantDAO.hostnameTenant("www.example.com")
+	s.NoError(err)
+	s.truncateTable(tenantTable)
+
+	// when
+	err = tenantDAO.Save(s.sessionEvent("", "2022-08-27 05:52:56", beacon.EventTypeVisitPage, "https://www.example.com/", 2000))
+	s.NoError(err)
+	// and
+	sleep()
+
+	// then
+	s.Equal(1, s.countRows(tenantTable))
+	s.Equal(1, s.countRows(baseTableName))
+}
+
+func (s *daoTestSuite) Test_existingTenants() {
+	// given
+	strategy, err := NewStrategy(string(AllInOneDB), string(TablePerHostname), "default")
+	s.NoError(err)
+	tenantDAO := New(s.dao.conn, Opts(s.dao.prefix, s.dao.cluster), WithStrategy(strategy, s.migrationDAO))
+	err = tenantDAO.SaveHost(beacon.NewHostnameEvent("missing.example.com", "2022-08-27 05:52:55"))
+	s.NoError(err)
+
+	// when
+	tenants, err := tenantDAO.existingTenants()
+
+	// then
+	s.NoError(err)
+	s.Equal([]tenant{tenantDAO.defaultTenant()}, tenants)
+	s.Empty(tenantDAO.tenants)
+}
+
+func (s *daoTestSuite) sessionEvent(sessionID, createdAt, eventType, url string, lcp uint16) beacon.RumEvent {
+	columns := map[string]any{}
+	if sessionID != "" {
+		columns["session_id"] = sessionID
+	}
+	if lcp > 0 {
+		columns["largest_contentful_paint"] = lcp
+	}
+	return beacon.RumEvent{
+		Created_At:  createdAt,
+		Hostname:    "www.example.com",
+		Url:         url,
+		Event_Type:  eventType,
+		Device_Type: beacon.DeviceTypeDesktop,
+		Columns:     columns,
+	}
+}
+
 func sleep() {
 	time.Sleep(sleepDuration)
 }
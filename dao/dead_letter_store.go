@@ -0,0 +1,160 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/basicrum/front_basicrum_go/deadletter"
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const baseDeadLettersTableName = "webperf_rum_dead_letters"
+
+// NewDeadLetter creates dead letter storage by type
+// nolint: revive
+func NewDeadLetter(storage deadletter.Storage, path string, conn clickhouse.Conn, opts *opts) (deadletter.IStore, error) {
+	switch storage {
+	case deadletter.StorageNone:
+		return deadletter.NewNullStore(), nil
+	case deadletter.StorageFile:
+		return deadletter.NewFileStore(path)
+	case deadletter.StorageClickHouse:
+		return NewDeadLetterStore(conn, opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported dead letter storage[%v]", storage)
+	}
+}
+
+// DeadLetterStore stores the dead letter entries in clickhouse table
+type DeadLetterStore struct {
+	conn    clickhouse.Conn
+	table   string
+	cluster string
+}
+
+// NewDeadLetterStore creates dead letter clickhouse storage
+func NewDeadLetterStore(conn clickhouse.Conn, opts *opts) *DeadLetterStore {
+	return &DeadLetterStore{
+		conn:    conn,
+		table:   opts.prefix + baseDeadLettersTableName,
+		cluster: opts.cluster,
+	}
+}
+
+// Store inserts the entry into dead letters table
+func (s *DeadLetterStore) Store(entry deadletter.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(
+		"INSERT INTO %s SETTINGS input_format_skip_unknown_fields = true FORMAT JSONEachRow %s",
+		s.table,
+		data,
+	)
+	err = s.conn.AsyncInsert(context.Background(), query, false)
+	if err != nil {
+		return fmt.Errorf("clickhouse insert failed: %w", err)
+	}
+	return nil
+}
+
+// List selects the oldest entries
+func (s *DeadLetterStore) List(limit int) ([]deadletter.Entry, error) {
+	query := fmt.Sprintf(
+		"SELECT id, toString(failed_at), table_name, data, error, parameters FROM %s ORDER BY failed_at",
+		s.table,
+	)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := s.conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("get dead letters failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []deadletter.Entry
+	for rows.Next() {
+		var item deadletter.Entry
+		if err := rows.Scan(&item.ID, &item.Failed_At, &item.Table_Name, &item.Data, &item.Error, &item.Parameters); err != nil {
+			return result, fmt.Errorf("get dead letters failed: %w", err)
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// Resubmit submits all the entries and deletes the submitted entries
+func (s *DeadLetterStore) Resubmit(submit func(entry deadletter.Entry) error) (int, error) {
+	entries, err := s.List(0)
+	if err != nil {
+		return 0, err
+	}
+	var submitted []string
+	for _, entry := range entries {
+		if err := submit(entry); err != nil {
+			log.Printf("resubmit dead letter id[%v] err[%v]", entry.ID, err)
+			continue
+		}
+		submitted = append(submitted, entry.ID)
+	}
+	if len(submitted) == 0 {
+		return 0, nil
+	}
+	if err := s.delete(submitted); err != nil {
+		return 0, err
+	}
+	return len(submitted), nil
+}
+
+// Erase deletes the entries with the erased rows and inserts the entries with the remaining rows
+func (s *DeadLetterStore) Erase(request types.ErasureRequest) (int, error) {
+	entries, err := s.List(0)
+	if err != nil {
+		return 0, err
+	}
+	matcher := request.Matcher()
+	var changed []string
+	var kept []deadletter.Entry
+	removed := 0
+	for _, entry := range entries {
+		entry, count := deadletter.EraseRows(entry, matcher)
+		if count == 0 {
+			continue
+		}
+		removed += count
+		changed = append(changed, entry.ID)
+		if entry.Data != "" {
+			kept = append(kept, entry)
+		}
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	if err := s.delete(changed); err != nil {
+		return 0, err
+	}
+	for _, entry := range kept {
+		if err := s.Store(entry); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+func (s *DeadLetterStore) delete(ids []string) error {
+	table := s.table
+	if s.cluster != "" {
+		table += localTableSuffix
+	}
+	query := fmt.Sprintf("DELETE FROM %s%s WHERE id IN ?", table, clusterClause(s.cluster))
+	if err := s.conn.Exec(context.Background(), query, inSet(ids)); err != nil {
+		return fmt.Errorf("delete dead letters failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+// erasureTables contain the session_id column, all except the sessions table contain the page_id column
+// nolint: gochecknoglobals
+var erasureTables = []string{
+	baseTableName,
+	baseXhrTableName,
+	baseErrorsTableName,
+	baseSessionsTableName,
+}
+
+// Erase starts the mutations deleting the erased rows from all tables with session_id column
+// The page views are deleted by page_id column, the aggregated sessions of the page views are deleted whole
+// The mutations are asynchronous, see ErasureStatus
+func (p *DAO) Erase(request types.ErasureRequest) error {
+	tenants, err := p.existingTenants()
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		pageSessionIDs, err := p.pageSessionIDs(t, request.PageIDs)
+		if err != nil {
+			return err
+		}
+		for _, table := range erasureTables {
+			sessionIDs := request.SessionIDs
+			if table == baseSessionsTableName {
+				sessionIDs = append(append([]string{}, request.SessionIDs...), pageSessionIDs...)
+			}
+			condition, args := erasureCondition(table, sessionIDs, request.PageIDs)
+			if condition == "" {
+				continue
+			}
+			query := fmt.Sprintf("ALTER TABLE %s%s DELETE WHERE %s", p.localTable(t, table), p.onCluster(), condition)
+			if err := p.conn.Exec(context.Background(), query, args...); err != nil {
+				return fmt.Errorf("erase table[%v] err[%w]", t.table(table), err)
+			}
+		}
+	}
+	return nil
+}
+
+// pageSessionIDs gets the sessions of the page views, the sessions table has no page_id column
+func (p *DAO) pageSessionIDs(t tenant, pageIDs []string) ([]string, error) {
+	if len(pageIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf(
+		"SELECT DISTINCT session_id FROM %s WHERE page_id IN ? AND session_id != toFixedString('', 43)",
+		t.table(baseTableName),
+	)
+	rows, err := p.conn.Query(context.Background(), query, inSet(pageIDs))
+	if err != nil {
+		return nil, fmt.Errorf("get page sessions failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var sessionID string
+		if err := rows.Scan(&sessionID); err != nil {
+			return result, fmt.Errorf("get page sessions failed: %w", err)
+		}
+		result = append(result, sessionID)
+	}
+	return result, rows.Err()
+}
+
+// ErasureStatus gets the remaining erased rows and the unfinished mutations of the tables
+func (p *DAO) ErasureStatus(request types.ErasureRequest) ([]types.ErasureStatus, error) {
+	tenants, err := p.existingTenants()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	result := make([]types.ErasureStatus, 0, len(tenants)*len(erasureTables))
+	for _, t := range tenants {
+		for _, table := range erasureTables {
+			status := types.ErasureStatus{Table: t.table(table)}
+			if condition, args := erasureCondition(table, request.SessionIDs, request.PageIDs); condition != "" {
+				query := fmt.Sprintf("SELECT count() FROM %s WHERE %s", status.Table, condition)
+				if err := p.conn.QueryRow(ctx, query, args...).Scan(&status.RemainingRows); err != nil {
+					return nil, fmt.Errorf("get erasure status table[%v] err[%w]", status.Table, err)
+				}
+			}
+			query := fmt.Sprintf(`SELECT count(), max(latest_fail_reason) FROM %s
+			WHERE %s AND table = ? AND NOT is_done`, p.mutationsTable(), t.databaseCondition())
+			if err := p.conn.QueryRow(ctx, query, p.localName(t, table)).Scan(&status.PendingMutations, &status.FailReason); err != nil {
+				return nil, fmt.Errorf("get erasure status table[%v] err[%w]", status.Table, err)
+			}
+			result = append(result, status)
+		}
+	}
+	return result, nil
+}
+
+// erasureCondition returns the condition of the erased rows of the table, it is empty when nothing is erased
+func erasureCondition(table string, sessionIDs, pageIDs []string) (string, []any) {
+	var conditions []string
+	var args []any
+	if len(sessionIDs) > 0 {
+		conditions = append(conditions, "session_id IN ?")
+		args = append(args, inSet(sessionIDs))
+	}
+	if len(pageIDs) > 0 && table != baseSessionsTableName {
+		conditions = append(conditions, "page_id IN ?")
+		args = append(args, inSet(pageIDs))
+	}
+	return strings.Join(conditions, " OR "), args
+}
+
+// mutationsTable returns the mutations of all cluster nodes
+func (p *DAO) mutationsTable() string {
+	if p.cluster == "" {
+		return "system.mutations"
+	}
+	return fmt.Sprintf("clusterAllReplicas('%s', system.mutations)", p.cluster)
+}
+
+// inSet binds the values as (value1, value2) for the IN operator
+func inSet(values []string) clickhouse.GroupSet {
+	result := clickhouse.GroupSet{Value: make([]any, 0, len(values))}
+	for _, value := range values {
+		result.Value = append(result.Value, value)
+	}
+	return result
+}
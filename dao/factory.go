@@ -1,17 +1,21 @@
 package dao
 
 import (
+	"net"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/basicrum/front_basicrum_go/config"
+	"github.com/basicrum/front_basicrum_go/deadletter"
 )
 
 type server struct {
-	host string
-	port int16
-	db   string
-}
-
-func (s *server) addr() string {
-	return s.host + ":" + strconv.FormatInt(int64(s.port), 10)
+	// addrs are the host:port addresses of the cluster nodes
+	addrs []string
+	db    string
 }
 
 type auth struct {
@@ -20,13 +24,30 @@ type auth struct {
 }
 
 type opts struct {
-	prefix string
+	prefix  string
+	cluster string
 }
 
 // Server creates the datastore (click house) options
+// The host is comma separated list of the cluster nodes, the nodes without port use the port
 // nolint: revive
 func Server(host string, port int16, db string) server {
-	return server{host, port, db}
+	defaultPort := strconv.FormatInt(int64(port), 10)
+	var addrs []string
+	for _, node := range strings.Split(host, ",") {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(node); err != nil {
+			node = net.JoinHostPort(node, defaultPort)
+		}
+		addrs = append(addrs, node)
+	}
+	if len(addrs) == 0 {
+		addrs = append(addrs, host+":"+defaultPort)
+	}
+	return server{addrs, db}
 }
 
 // Auth creates the authentication options for persistance service
@@ -36,7 +57,90 @@ func Auth(user string, pwd string) auth {
 }
 
 // Opts creates the options for persistance service
+// The tables are replicated and distributed on the cluster when the cluster name is not empty
 // nolint: revive
-func Opts(prefix string) *opts {
-	return &opts{prefix}
+func Opts(prefix, cluster string) *opts {
+	return &opts{prefix, cluster}
+}
+
+// Database contains the datastore (click house) settings of the startup configuration
+// The server and the commands use it to create the connection, the migrator and the DAO in the same way
+type Database struct {
+	server          server
+	auth            auth
+	connection      *connection
+	opts            *opts
+	strategy        Strategy
+	autoMigrate     bool
+	asyncInsertWait bool
+}
+
+// FromConfig creates the datastore settings from the startup configuration
+func FromConfig(sConf *config.StartupConfig) (*Database, error) {
+	c, err := Connection(
+		WithConnOpenStrategy(sConf.Database.ConnOpenStrategy),
+		WithProtocol(sConf.Database.Protocol, sConf.Database.NativePort),
+		WithCompression(sConf.Database.Compression),
+		WithTLS(
+			sConf.Database.TLS,
+			sConf.Database.TLSCAFile,
+			sConf.Database.TLSCertFile,
+			sConf.Database.TLSKeyFile,
+			sConf.Database.TLSSkipVerify,
+		),
+		WithTimeouts(
+			time.Duration(sConf.Database.DialTimeoutSeconds)*time.Second,
+			time.Duration(sConf.Database.ReadTimeoutSeconds)*time.Second,
+		),
+		WithPool(sConf.Database.MaxOpenConns, sConf.Database.MaxIdleConns),
+	)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := NewStrategy(sConf.Persistance.DatabaseStrategy, sConf.Persistance.TableStrategy, sConf.Database.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{
+		server:          Server(sConf.Database.Host, sConf.Database.Port, sConf.Database.DatabaseName),
+		auth:            Auth(sConf.Database.Username, sConf.Database.Password),
+		connection:      c,
+		opts:            Opts(sConf.Database.TablePrefix, sConf.Database.Cluster),
+		strategy:        strategy,
+		autoMigrate:     sConf.Database.AutoMigrate,
+		asyncInsertWait: sConf.Database.AsyncInsertWait,
+	}, nil
+}
+
+// Connect opens the connection of the DAO and the dead letter store
+func (d *Database) Connect() (clickhouse.Conn, error) {
+	return NewConnection(d.server, d.auth, d.connection)
+}
+
+// Migration creates the migrator of the configured tables
+func (d *Database) Migration() *MigrationDAO {
+	return NewMigrationDAO(d.server, d.auth, d.connection, d.opts)
+}
+
+// TenantMigrator returns the migrator of the new hostname tables, it is nil when the auto migration is disabled
+func (d *Database) TenantMigrator() ITenantMigrator {
+	if !d.autoMigrate {
+		return nil
+	}
+	return d.Migration()
+}
+
+// DAO creates the DAO with the persistance strategy, the nil migrator does not create the tables of the new hostnames
+func (d *Database) DAO(conn clickhouse.Conn, migrator ITenantMigrator) *DAO {
+	return New(conn, d.opts, WithStrategy(d.strategy, migrator), WithAsyncInsertWait(d.asyncInsertWait))
+}
+
+// DeadLetter creates the dead letter store of the storage
+func (d *Database) DeadLetter(storage deadletter.Storage, path string, conn clickhouse.Conn) (deadletter.IStore, error) {
+	return NewDeadLetter(storage, path, conn, d.opts)
+}
+
+// Strategy returns the persistance strategy
+func (d *Database) Strategy() Strategy {
+	return d.strategy
 }
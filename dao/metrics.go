@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+// webVital contains the events table column and the good and poor thresholds of the Web Vital
+// https://web.dev/articles/defining-core-web-vitals-thresholds
+type webVital struct {
+	name   string
+	column string
+	good   float64
+	poor   float64
+}
+
+// nolint: gochecknoglobals
+var webVitals = []webVital{
+	{name: "lcp", column: "largest_contentful_paint", good: 2500, poor: 4000},
+	{name: "fcp", column: "first_contentful_paint", good: 1800, poor: 3000},
+	{name: "ttfb", column: "first_byte_duration", good: 800, poor: 1800},
+	{name: "cls", column: "cumulative_layout_shift", good: 0.1, poor: 0.25},
+	{name: "inp", column: "interaction_to_next_paint", good: 200, poor: 500},
+	{name: "fid", column: "first_input_delay", good: 100, poor: 300},
+}
+
+// urlPatternReplacer escapes the LIKE special characters and converts * wildcard
+// nolint: gochecknoglobals
+var urlPatternReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+
+// Metrics calculates the Web Vitals percentiles and rating shares of the filtered page views
+func (p *DAO) Metrics(filter types.MetricsFilter) (*types.Metrics, error) {
+	t, err := p.existingTenant(filter.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	where, args := metricsWhere(filter)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", metricsColumns(), p.metricsSource(t), where)
+	rows, err := p.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get metrics hostname[%v] failed: %w", filter.Hostname, err)
+	}
+	defer rows.Close()
+
+	result := &types.Metrics{
+		Hostname: filter.Hostname,
+		From:     filter.From,
+		To:       filter.To,
+	}
+	if !rows.Next() {
+		return result, rows.Err()
+	}
+	quantiles := make([][]float64, len(webVitals))
+	ratings := make([][3]uint64, len(webVitals))
+	result.Vitals = make([]types.VitalMetrics, len(webVitals))
+	dest := []any{&result.PageViews}
+	for i := range webVitals {
+		dest = append(dest, &result.Vitals[i].Samples, &quantiles[i], &ratings[i][0], &ratings[i][1], &ratings[i][2])
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("get metrics hostname[%v] failed: %w", filter.Hostname, err)
+	}
+	for i, vital := range webVitals {
+		result.Vitals[i] = newVitalMetrics(vital.name, result.Vitals[i].Samples, quantiles[i], ratings[i])
+	}
+	return result, rows.Err()
+}
+
+// metricsSource reads the events of the hostname tables and the events saved to the default tables before the hostname was registered
+func (p *DAO) metricsSource(t tenant) string {
+	if t == p.defaultTenant() {
+		return t.table(baseTableName)
+	}
+	return fmt.Sprintf("(SELECT * FROM %s UNION ALL SELECT * FROM %s)", t.table(baseTableName), p.defaultTenant().table(baseTableName))
+}
+
+// metricsColumns rates the Web Vitals by the thresholds converted to Float32
+// The Float32 CLS 0.1 is greater than the Float64 literal 0.1, so it would be rated as needs improvement
+func metricsColumns() string {
+	columns := []string{"count()"}
+	for _, vital := range webVitals {
+		columns = append(columns,
+			fmt.Sprintf("count(%s)", vital.column),
+			fmt.Sprintf("quantiles(0.5, 0.75, 0.95)(%s)", vital.column),
+			fmt.Sprintf("countIf(%s <= toFloat32(%v))", vital.column, vital.good),
+			fmt.Sprintf("countIf(%s > toFloat32(%v) AND %s <= toFloat32(%v))", vital.column, vital.good, vital.column, vital.poor),
+			fmt.Sprintf("countIf(%s > toFloat32(%v))", vital.column, vital.poor),
+		)
+	}
+	return strings.Join(columns, ", ")
+}
+
+func metricsWhere(filter types.MetricsFilter) (string, []any) {
+	conditions := []string{
+		"hostname = ?",
+		"created_at >= toDateTime(?)",
+		"created_at < toDateTime(?)",
+		"event_type IN ('visit_page', 'spa_hard', 'spa')",
+	}
+	args := []any{filter.Hostname, filter.From.Unix(), filter.To.Unix()}
+	if filter.URLPattern != "" {
+		conditions = append(conditions, "url LIKE ?")
+		args = append(args, urlPatternReplacer.Replace(filter.URLPattern))
+	}
+	if filter.DeviceType != "" {
+		conditions = append(conditions, "device_type = ?")
+		args = append(args, filter.DeviceType)
+	}
+	if filter.CountryCode != "" {
+		conditions = append(conditions, "geo_country_code = ?")
+		args = append(args, filter.CountryCode)
+	}
+	if filter.BrowserName != "" {
+		conditions = append(conditions, "browser_name = ?")
+		args = append(args, filter.BrowserName)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func newVitalMetrics(name string, samples uint64, quantiles []float64, ratings [3]uint64) types.VitalMetrics {
+	result := types.VitalMetrics{
+		Name:    name,
+		Samples: samples,
+	}
+	if samples == 0 || len(quantiles) != 3 {
+		return result
+	}
+	result.P50 = &quantiles[0]
+	result.P75 = &quantiles[1]
+	result.P95 = &quantiles[2]
+	result.Good = float64(ratings[0]) / float64(samples)
+	result.NeedsImprovement = float64(ratings[1]) / float64(samples)
+	result.Poor = float64(ratings[2]) / float64(samples)
+	return result
+}
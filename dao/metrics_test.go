@@ -0,0 +1,24 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_metricsColumns(t *testing.T) {
+	got := metricsColumns()
+	require.Contains(t, got, "countIf(cumulative_layout_shift <= toFloat32(0.1))")
+	require.Contains(t, got, "countIf(cumulative_layout_shift > toFloat32(0.1) AND cumulative_layout_shift <= toFloat32(0.25))")
+	require.Contains(t, got, "countIf(largest_contentful_paint > toFloat32(4000))")
+}
+
+func TestDAO_metricsSource(t *testing.T) {
+	p := &DAO{prefix: "prefix_"}
+
+	require.Equal(t, "prefix_webperf_rum_events", p.metricsSource(p.defaultTenant()))
+	require.Equal(t,
+		"(SELECT * FROM default_www_example_com_80fc0fb9.webperf_rum_events UNION ALL SELECT * FROM prefix_webperf_rum_events)",
+		p.metricsSource(tenant{database: "default_www_example_com_80fc0fb9"}),
+	)
+}
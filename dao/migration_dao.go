@@ -10,40 +10,223 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/uptrace/go-clickhouse/ch"
 	"github.com/uptrace/go-clickhouse/chdebug"
 	"github.com/uptrace/go-clickhouse/chmigrate"
 
 	"github.com/basicrum/front_basicrum_go/templatemigrations"
+	"github.com/basicrum/front_basicrum_go/types"
 )
 
 // MigrationDAO is data access object for clickhouse database
 type MigrationDAO struct {
-	migrateDatabaseURL string
-	prefix             string
+	server     server
+	auth       auth
+	connection *connection
+	prefix     string
+	cluster    string
+	// statePrefix is the prefix of the migration state tables, it contains the configured database for the tenant databases
+	statePrefix string
+	// tables limits the migrations to the migrations of the tables, nil applies all migrations
+	tables map[string]struct{}
 }
 
 // New creates persistance service
 // nolint: revive
-func NewMigrationDAO(s server, a auth, opts *opts) *MigrationDAO {
+func NewMigrationDAO(s server, a auth, c *connection, opts *opts) *MigrationDAO {
 	return &MigrationDAO{
-		migrateDatabaseURL: migrateDBURL(s, a),
-		prefix:             opts.prefix,
+		server:      s,
+		auth:        a,
+		connection:  c,
+		prefix:      opts.prefix,
+		cluster:     opts.cluster,
+		statePrefix: opts.prefix,
 	}
 }
 
-func migrateDBURL(s server, a auth) string {
+// migrateDBURL creates the DSN of the node, the TLS of the DSN is replaced by the connection settings
+func migrateDBURL(addr, db string, a auth, c *connection) string {
 	return fmt.Sprintf("clickhouse://%v:%v@%v/%v?sslmode=disable",
 		a.user,
 		a.pwd,
-		s.addr(),
-		s.db,
+		c.migratorAddr(addr),
+		db,
 	)
 }
 
+// connect opens the migrator connection of the first available node with the connection settings of the DAO
+// The node responding with the clickhouse error, for example the missing database, is available
+func (p *MigrationDAO) connect(s server, options ...ch.Option) (*ch.DB, error) {
+	var err error
+	for _, addr := range s.addrs {
+		result := []ch.Option{ch.WithDSN(migrateDBURL(addr, s.db, p.auth, p.connection))}
+		result = append(result, p.connection.migratorOptions()...)
+		db := ch.Connect(append(result, options...)...)
+		err = db.Ping(context.Background())
+		var chErr *ch.Error
+		if err == nil || errors.As(err, &chErr) {
+			return db, nil
+		}
+		log.Printf("migrator node[%v] is not available err[%v]", addr, err)
+		_ = db.Close()
+	}
+	return nil, fmt.Errorf("migrator cannot connect nodes[%v] err[%w]", strings.Join(s.addrs, ","), err)
+}
+
+// Tenant creates the migration service of the hostname tables in the database with the table prefix
+// The empty database is the configured database. Only the migrations of the tenant tables and their views are applied,
+// the shared tables and the migration state stay in the configured database
+func (p *MigrationDAO) Tenant(database, prefix string) *MigrationDAO {
+	result := *p
+	result.prefix = prefix
+	result.statePrefix = prefix
+	result.tables = tenantMigrationTables
+	if database != "" {
+		result.server.db = database
+		result.statePrefix = p.server.db + "." + database + "_" + prefix
+	}
+	return &result
+}
+
 // Migrate applies all pending database migrations
 func (p *MigrationDAO) Migrate() error {
+	applied, err := p.Up(false)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Printf("there are no new migrations to run (database is up to date)\n")
+		return nil
+	}
+	log.Printf("migrated to %s\n", applied[len(applied)-1].Name)
+	return nil
+}
+
+// MigrateTenant applies the pending migrations of the prefixed tables, the database is created when it does not exist
+// The empty database is the configured database
+func (p *MigrationDAO) MigrateTenant(database, prefix string) error {
+	return p.Tenant(database, prefix).Migrate()
+}
+
+// Status gets all migrations in ascending order with the applied time
+func (p *MigrationDAO) Status() ([]types.MigrationStatus, error) {
+	var result []types.MigrationStatus
+	err := p.withMigrator(false, func(_ context.Context, _ *chmigrate.Migrator, migrations chmigrate.MigrationSlice, _ string) error {
+		for i := range migrations {
+			result = append(result, newMigrationStatus(&migrations[i]))
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Up applies the pending migrations in ascending order
+// The dry run returns the pending migrations with the SQL without applying them
+func (p *MigrationDAO) Up(dryRun bool) ([]types.MigrationStatus, error) {
+	var result []types.MigrationStatus
+	err := p.withMigrator(!dryRun, func(
+		ctx context.Context,
+		migrator *chmigrate.Migrator,
+		migrations chmigrate.MigrationSlice,
+		sourcePath string,
+	) error {
+		var err error
+		if dryRun {
+			result, err = migrationsSQL(migrations.Unapplied(), sourcePath, "up")
+			return err
+		}
+		group, err := migrator.Migrate(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range group.Migrations {
+			result = append(result, newMigrationStatus(&group.Migrations[i]))
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Down rolls back the last n applied migrations in descending order
+// The dry run returns the migrations with the rollback SQL without running it
+func (p *MigrationDAO) Down(n int, dryRun bool) ([]types.MigrationStatus, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of migrations[%v]", n)
+	}
+	var result []types.MigrationStatus
+	err := p.withMigrator(!dryRun, func(
+		ctx context.Context,
+		migrator *chmigrate.Migrator,
+		migrations chmigrate.MigrationSlice,
+		sourcePath string,
+	) error {
+		var err error
+		applied := migrations.Applied()
+		if n < len(applied) {
+			applied = applied[:n]
+		}
+		if dryRun {
+			result, err = migrationsSQL(applied, sourcePath, "down")
+			return err
+		}
+		for i := range applied {
+			migration := &applied[i]
+			if migration.Down != nil {
+				if err := migration.Down(ctx, migrator.DB()); err != nil {
+					return fmt.Errorf("cannot roll back migration[%v] err[%w]", migration, err)
+				}
+			}
+			if err := migrator.MarkUnapplied(ctx, migration); err != nil {
+				return err
+			}
+			result = append(result, types.MigrationStatus{Name: migration.String()})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// MarkApplied marks the pending migrations as applied without running them, it is used when the tables are created separately
+// The empty name marks all pending migrations, the dry run returns the pending migrations without marking them
+func (p *MigrationDAO) MarkApplied(name string, dryRun bool) ([]types.MigrationStatus, error) {
+	var result []types.MigrationStatus
+	err := p.withMigrator(!dryRun, func(
+		ctx context.Context,
+		migrator *chmigrate.Migrator,
+		migrations chmigrate.MigrationSlice,
+		_ string,
+	) error {
+		groupID := migrations.LastGroupID() + 1
+		pending := migrations.Unapplied()
+		for i := range pending {
+			migration := &pending[i]
+			if name != "" && name != migration.Name && name != migration.String() {
+				continue
+			}
+			if !dryRun {
+				migration.GroupID = groupID
+				if err := migrator.MarkApplied(ctx, migration); err != nil {
+					return err
+				}
+			}
+			result = append(result, newMigrationStatus(migration))
+		}
+		if name != "" && len(result) == 0 {
+			return fmt.Errorf("migration[%v] is not pending", name)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// withMigrator calls f with the migrator and the migrations with status, the table prefix is replaced in the migrations
+// The write creates the database and the changelog tables and locks the migrations, otherwise the database is not changed
+func (p *MigrationDAO) withMigrator(
+	write bool,
+	f func(ctx context.Context, migrator *chmigrate.Migrator, migrations chmigrate.MigrationSlice, sourcePath string) error,
+) error {
 	tempDir, err := os.MkdirTemp("", "migrations")
 	if err != nil {
 		return fmt.Errorf("cannot create temp directory migrations err[%w]", err)
@@ -53,7 +236,93 @@ func (p *MigrationDAO) Migrate() error {
 	if err != nil {
 		return fmt.Errorf("cannot copy migrations err[%w]", err)
 	}
-	return p.migrateUp(tempDir)
+
+	if write && p.cluster != "" {
+		if err := p.createClusterDatabase(); err != nil {
+			return err
+		}
+	}
+
+	db, err := p.connect(p.server, ch.WithAutoCreateDatabase(write))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	db.AddQueryHook(chdebug.NewQueryHook(
+		chdebug.WithEnabled(false),
+		chdebug.FromEnv("CHDEBUG"),
+	))
+
+	var migrations = chmigrate.NewMigrations()
+	if err := migrations.Discover(os.DirFS(tempDir)); err != nil {
+		return fmt.Errorf("cannot discover migrations path[%v] err[%w]", tempDir, err)
+	}
+
+	migrator := chmigrate.NewMigrator(db, migrations,
+		chmigrate.WithTableName(p.statePrefix+"ch_migrations"),
+		chmigrate.WithLocksTableName(p.statePrefix+"ch_migration_locks"),
+	)
+
+	ctx := context.Background()
+
+	if write {
+		// create ch_migrations (changelog) and ch_migration_locks tables
+		if err := migrator.Init(ctx); err != nil {
+			return err
+		}
+		// lock the migrations
+		if err := migrator.Lock(ctx); err != nil {
+			return err
+		}
+		// unlock the migrations
+		defer func() {
+			if err := migrator.Unlock(ctx); err != nil {
+				log.Printf("received unlock err[%v]\n", err)
+			}
+		}()
+	}
+
+	withStatus := migrations.Sorted()
+	var exists uint8
+	if err := db.QueryRowContext(ctx, "EXISTS TABLE ?", ch.Safe(p.statePrefix+"ch_migrations")).Scan(&exists); err != nil {
+		return fmt.Errorf("cannot check migrations table err[%w]", err)
+	}
+	if exists == 1 {
+		withStatus, err = migrator.MigrationsWithStatus(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return f(ctx, migrator, withStatus, tempDir)
+}
+
+func newMigrationStatus(migration *chmigrate.Migration) types.MigrationStatus {
+	result := types.MigrationStatus{
+		Name:    migration.String(),
+		Applied: migration.IsApplied(),
+	}
+	if result.Applied {
+		migratedAt := migration.MigratedAt
+		result.MigratedAt = &migratedAt
+	}
+	return result
+}
+
+// migrationsSQL reads the up or down SQL files of the migrations
+func migrationsSQL(migrations chmigrate.MigrationSlice, sourcePath, direction string) ([]types.MigrationStatus, error) {
+	result := make([]types.MigrationStatus, 0, len(migrations))
+	for i := range migrations {
+		status := newMigrationStatus(&migrations[i])
+		filename := filepath.Join(sourcePath, fmt.Sprintf("%s.%s.sql", migrations[i].String(), direction))
+		sql, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migration file[%v] err[%w]", filename, err)
+		}
+		status.SQL = string(sql)
+		result = append(result, status)
+	}
+	return result, nil
 }
 
 // nolint: revive
@@ -62,7 +331,7 @@ func (p *MigrationDAO) copyMigrations(tempDir string) error {
 		if err != nil {
 			return err
 		}
-		if d.IsDir() {
+		if d.IsDir() || !p.migratesFile(path) {
 			return nil
 		}
 
@@ -80,6 +349,18 @@ func (p *MigrationDAO) copyMigrations(tempDir string) error {
 	})
 }
 
+// migratesFile checks if the migration file changes the migrated tables
+// The file name is <version>_<table>.<direction>.sql
+func (p *MigrationDAO) migratesFile(filename string) bool {
+	if p.tables == nil {
+		return true
+	}
+	_, name, _ := strings.Cut(filename, "_")
+	table, _, _ := strings.Cut(name, ".")
+	_, ok := p.tables[table]
+	return ok
+}
+
 func (p *MigrationDAO) processMigrationFile(srcFile fs.File, tempDir, filename string) error {
 	// build source and destination file paths
 	dstFile := filepath.Join(tempDir, filename)
@@ -96,6 +377,45 @@ func (p *MigrationDAO) processMigrationFile(srcFile fs.File, tempDir, filename s
 		return fmt.Errorf("cannot replace table prefix in migration file[%v] err[%w]", dstFile, err)
 	}
 
+	if p.cluster == "" {
+		return nil
+	}
+	return p.convertClusterFile(dstFile)
+}
+
+// convertClusterFile converts the migration file into the replicated and distributed tables statements
+func (p *MigrationDAO) convertClusterFile(file string) error {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("cannot read file stat file[%v] err[%w]", file, err)
+	}
+	input, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("cannot read migration file[%v] err[%w]", file, err)
+	}
+	output, err := clusterMigration(string(input), p.cluster, p.prefix)
+	if err != nil {
+		return fmt.Errorf("cannot convert cluster migration file[%v] err[%w]", file, err)
+	}
+	if err = os.WriteFile(file, []byte(output), stat.Mode()); err != nil {
+		return fmt.Errorf("cannot write cluster migration file[%v] err[%w]", file, err)
+	}
+	return nil
+}
+
+// createClusterDatabase creates the database on all cluster nodes
+func (p *MigrationDAO) createClusterDatabase() error {
+	systemServer := p.server
+	systemServer.db = "system"
+	db, err := p.connect(systemServer)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s%s", p.server.db, clusterClause(p.cluster))
+	if _, err := db.ExecContext(context.Background(), query); err != nil {
+		return fmt.Errorf("cannot create database[%v] err[%w]", p.server.db, err)
+	}
 	return nil
 }
 
@@ -155,56 +475,3 @@ func (*MigrationDAO) replaceTextInFile(file, find, replace string) error {
 	}
 	return nil
 }
-
-func (p *MigrationDAO) migrateUp(sourcePath string) error {
-	db := ch.Connect(ch.WithDSN(p.migrateDatabaseURL))
-
-	db.AddQueryHook(chdebug.NewQueryHook(
-		chdebug.WithEnabled(false),
-		chdebug.FromEnv("CHDEBUG"),
-	))
-
-	sourceFS := os.DirFS(sourcePath)
-	var migrations = chmigrate.NewMigrations()
-	if err := migrations.Discover(sourceFS); err != nil {
-		return fmt.Errorf("cannot discover migrations path[%v] err[%w]", sourcePath, err)
-	}
-
-	migrator := chmigrate.NewMigrator(db, migrations,
-		chmigrate.WithTableName(p.prefix+"ch_migrations"),
-		chmigrate.WithLocksTableName(p.prefix+"ch_migration_locks"),
-	)
-
-	ctx := context.Background()
-
-	// create ch_migrations (changelog) and ch_migration_locks tables
-	err := migrator.Init(ctx)
-	if err != nil {
-		return err
-	}
-
-	// lock the migrations
-	if err := migrator.Lock(ctx); err != nil {
-		return err
-	}
-	// unlock the migrations
-	defer func() {
-		if err := migrator.Unlock(ctx); err != nil {
-			log.Printf("received unlock err[%v]\n", err)
-		}
-	}()
-
-	// apply the migrations
-	group, err := migrator.Migrate(ctx)
-	if err != nil {
-		return err
-	}
-
-	if group.IsZero() {
-		log.Printf("there are no new migrations to run (database is up to date)\n")
-		return nil
-	}
-	log.Printf("migrated to %s\n", group)
-
-	return nil
-}
@@ -49,6 +49,21 @@ func (mr *MockIDAOMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockIDAO)(nil).Close))
 }
 
+// Columns mocks base method.
+func (m *MockIDAO) Columns() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Columns")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Columns indicates an expected call of Columns.
+func (mr *MockIDAOMockRecorder) Columns() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Columns", reflect.TypeOf((*MockIDAO)(nil).Columns))
+}
+
 // DeleteOwnerHostname mocks base method.
 func (m *MockIDAO) DeleteOwnerHostname(hostname, username string) error {
 	m.ctrl.T.Helper()
@@ -63,6 +78,35 @@ func (mr *MockIDAOMockRecorder) DeleteOwnerHostname(hostname, username interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOwnerHostname", reflect.TypeOf((*MockIDAO)(nil).DeleteOwnerHostname), hostname, username)
 }
 
+// Erase mocks base method.
+func (m *MockIDAO) Erase(request types.ErasureRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockIDAOMockRecorder) Erase(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockIDAO)(nil).Erase), request)
+}
+
+// ErasureStatus mocks base method.
+func (m *MockIDAO) ErasureStatus(request types.ErasureRequest) ([]types.ErasureStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ErasureStatus", request)
+	ret0, _ := ret[0].([]types.ErasureStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ErasureStatus indicates an expected call of ErasureStatus.
+func (mr *MockIDAOMockRecorder) ErasureStatus(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ErasureStatus", reflect.TypeOf((*MockIDAO)(nil).ErasureStatus), request)
+}
+
 // GetSubscription mocks base method.
 func (m *MockIDAO) GetSubscription(id string) (*types.SubscriptionWithHostname, error) {
 	m.ctrl.T.Helper()
@@ -107,6 +151,35 @@ func (mr *MockIDAOMockRecorder) InsertOwnerHostname(item interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOwnerHostname", reflect.TypeOf((*MockIDAO)(nil).InsertOwnerHostname), item)
 }
 
+// InsertRows mocks base method.
+func (m *MockIDAO) InsertRows(table string, rows []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRows", table, rows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertRows indicates an expected call of InsertRows.
+func (mr *MockIDAOMockRecorder) InsertRows(table, rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRows", reflect.TypeOf((*MockIDAO)(nil).InsertRows), table, rows)
+}
+
+// Metrics mocks base method.
+func (m *MockIDAO) Metrics(filter types.MetricsFilter) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Metrics", filter)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metrics indicates an expected call of Metrics.
+func (mr *MockIDAOMockRecorder) Metrics(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metrics", reflect.TypeOf((*MockIDAO)(nil).Metrics), filter)
+}
+
 // Save mocks base method.
 func (m *MockIDAO) Save(rumEvent beacon.RumEvent) error {
 	m.ctrl.T.Helper()
@@ -121,6 +194,20 @@ func (mr *MockIDAOMockRecorder) Save(rumEvent interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIDAO)(nil).Save), rumEvent)
 }
 
+// SaveErrors mocks base method.
+func (m *MockIDAO) SaveErrors(events []beacon.ErrorEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveErrors", events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveErrors indicates an expected call of SaveErrors.
+func (mr *MockIDAOMockRecorder) SaveErrors(events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveErrors", reflect.TypeOf((*MockIDAO)(nil).SaveErrors), events)
+}
+
 // SaveHost mocks base method.
 func (m *MockIDAO) SaveHost(event beacon.HostnameEvent) error {
 	m.ctrl.T.Helper()
@@ -134,3 +221,32 @@ func (mr *MockIDAOMockRecorder) SaveHost(event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHost", reflect.TypeOf((*MockIDAO)(nil).SaveHost), event)
 }
+
+// SaveXhr mocks base method.
+func (m *MockIDAO) SaveXhr(event beacon.XhrEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveXhr", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveXhr indicates an expected call of SaveXhr.
+func (mr *MockIDAOMockRecorder) SaveXhr(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveXhr", reflect.TypeOf((*MockIDAO)(nil).SaveXhr), event)
+}
+
+// SeenHostnames mocks base method.
+func (m *MockIDAO) SeenHostnames(days int, unregisteredOnly bool) ([]types.SeenHostname, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SeenHostnames", days, unregisteredOnly)
+	ret0, _ := ret[0].([]types.SeenHostname)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SeenHostnames indicates an expected call of SeenHostnames.
+func (mr *MockIDAOMockRecorder) SeenHostnames(days, unregisteredOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SeenHostnames", reflect.TypeOf((*MockIDAO)(nil).SeenHostnames), days, unregisteredOnly)
+}
@@ -0,0 +1,43 @@
+package dao
+
+import (
+	"encoding/json"
+
+	"github.com/basicrum/front_basicrum_go/beacon"
+)
+
+// Rejecter returns the rows of the events as InsertError without inserting them
+// It is used to store the events which cannot be queued for the insert in dead letter storage
+type Rejecter struct {
+	Err error
+}
+
+// Save returns the event row
+func (r Rejecter) Save(rumEvent beacon.RumEvent) error {
+	data, err := eventRows(rumEvent)
+	if err != nil {
+		return err
+	}
+	return &InsertError{Table: baseTableName, Rows: data, Err: r.Err}
+}
+
+// SaveXhr returns the xhr row
+func (r Rejecter) SaveXhr(event beacon.XhrEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return &InsertError{Table: baseXhrTableName, Rows: data, Err: r.Err}
+}
+
+// SaveErrors returns the error rows
+func (r Rejecter) SaveErrors(events []beacon.ErrorEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	data, err := errorRows(events)
+	if err != nil {
+		return err
+	}
+	return &InsertError{Table: baseErrorsTableName, Rows: data, Err: r.Err}
+}
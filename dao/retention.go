@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+// retentionTables contain the event_date and hostname columns
+// nolint: gochecknoglobals
+var retentionTables = []string{
+	baseTableName,
+	baseXhrTableName,
+	baseErrorsTableName,
+	baseSessionsTableName,
+}
+
+// ApplyRetention modifies the TTL of the tables, the TTL is removed when the retention is disabled
+// The unchanged TTL is not modified again because the modification rewrites the existing parts
+// The existing hostname tenants are changed too, the new hostname tenants are changed when they are used first time
+// The TTL of the cluster is set on the local tables
+func (p *DAO) ApplyRetention(retention types.Retention) error {
+	p.tenantsMu.Lock()
+	p.retention = &retention
+	p.tenantsMu.Unlock()
+	tenants, err := p.existingTenants()
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		if err := p.applyRetention(t, retention); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *DAO) applyRetention(t tenant, retention types.Retention) error {
+	ttl := retentionTTL(retention)
+	for _, table := range retentionTables {
+		current, err := p.tableTTL(t, table)
+		if err != nil {
+			return err
+		}
+		if normalizeTTL(current) == normalizeTTL(ttl) {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s%s MODIFY TTL %s", p.localTable(t, table), p.onCluster(), ttl)
+		if ttl == "" {
+			query = fmt.Sprintf("ALTER TABLE %s%s REMOVE TTL", p.localTable(t, table), p.onCluster())
+		}
+		if err := p.conn.Exec(context.Background(), query); err != nil {
+			return fmt.Errorf("apply retention table[%v] ttl[%v] err[%w]", t.table(table), ttl, err)
+		}
+		log.Printf("applied retention table[%v] ttl[%v]", t.table(table), ttl)
+	}
+	return nil
+}
+
+// RetentionReport gets the partitions with the rows which expire by the retention
+func (p *DAO) RetentionReport(retention types.Retention) ([]types.RetentionPartition, error) {
+	var result []types.RetentionPartition
+	if !retention.Enabled() {
+		return result, nil
+	}
+	tenants, err := p.existingTenants()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tenants {
+		for _, table := range retentionTables {
+			partitions, err := p.retentionPartitions(t.table(table), retention)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, partitions...)
+		}
+	}
+	return result, nil
+}
+
+// retentionPartitions groups the rows by the partition id, because the events are partitioned by day and the sessions by month
+// The partition is removed by TTL only when all its rows expire
+func (p *DAO) retentionPartitions(table string, retention types.Retention) ([]types.RetentionPartition, error) {
+	expired := fmt.Sprintf("%s <= today()", retentionExpression(retention))
+	if where := retentionWhere(retention); where != "" {
+		expired += " AND " + where
+	}
+	query := fmt.Sprintf(`
+	SELECT _partition_id AS partition, count() AS rows, countIf(%s) AS expired_rows
+	FROM %s
+	GROUP BY partition
+	HAVING expired_rows > 0
+	ORDER BY partition`,
+		expired,
+		table,
+	)
+	rows, err := p.conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("get retention report table[%v] failed: %w", table, err)
+	}
+	defer rows.Close()
+
+	var result []types.RetentionPartition
+	for rows.Next() {
+		item := types.RetentionPartition{Table: table}
+		if err := rows.Scan(&item.Partition, &item.Rows, &item.ExpiredRows); err != nil {
+			return nil, fmt.Errorf("get retention report table[%v] failed: %w", table, err)
+		}
+		item.Removed = item.Rows == item.ExpiredRows
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+func (p *DAO) tableTTL(t tenant, table string) (string, error) {
+	query := fmt.Sprintf("SELECT engine_full FROM system.tables WHERE %s AND name = ?", t.databaseCondition())
+	var engine string
+	if err := p.conn.QueryRow(context.Background(), query, p.localName(t, table)).Scan(&engine); err != nil {
+		return "", fmt.Errorf("get table[%v] engine failed: %w", p.localTable(t, table), err)
+	}
+	_, ttl, found := strings.Cut(engine, " TTL ")
+	if !found {
+		return "", nil
+	}
+	ttl, _, _ = strings.Cut(ttl, " SETTINGS ")
+	return ttl, nil
+}
+
+// retentionTTL creates the TTL expression
+// The hostnames without override are not deleted when the global retention is disabled
+func retentionTTL(retention types.Retention) string {
+	if !retention.Enabled() {
+		return ""
+	}
+	result := retentionExpression(retention)
+	if where := retentionWhere(retention); where != "" {
+		result += " WHERE " + where
+	}
+	return result
+}
+
+// retentionExpression creates addDays(event_date, multiIf(hostname = 'www.example.com', 30, 90))
+func retentionExpression(retention types.Retention) string {
+	hostnames := retention.SortedHostnames()
+	if len(hostnames) == 0 {
+		return fmt.Sprintf("addDays(event_date, %d)", retention.Days)
+	}
+	conditions := make([]string, 0, len(hostnames)*2+1)
+	for _, hostname := range hostnames {
+		conditions = append(conditions, fmt.Sprintf("hostname = '%s'", hostname), strconv.Itoa(retention.Hostnames[hostname]))
+	}
+	conditions = append(conditions, strconv.Itoa(retention.Days))
+	return fmt.Sprintf("addDays(event_date, multiIf(%s))", strings.Join(conditions, ", "))
+}
+
+func retentionWhere(retention types.Retention) string {
+	if retention.Days > 0 || len(retention.Hostnames) == 0 {
+		return ""
+	}
+	hostnames := retention.SortedHostnames()
+	return fmt.Sprintf("hostname IN ('%s')", strings.Join(hostnames, "', '"))
+}
+
+// normalizeTTL removes the formatting differences of ClickHouse table definition
+func normalizeTTL(ttl string) string {
+	return strings.NewReplacer(" ", "", "DELETE", "", "`", "").Replace(ttl)
+}
@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_retentionTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention types.Retention
+		want      string
+	}{
+		{
+			name: "disabled",
+			want: "",
+		},
+		{
+			name:      "global days",
+			retention: types.Retention{Days: 90},
+			want:      "addDays(event_date, 90)",
+		},
+		{
+			name:      "global days with hostnames",
+			retention: types.Retention{Days: 90, Hostnames: map[string]int{"www.example.com": 30, "shop.example.com": 365}},
+			want:      "addDays(event_date, multiIf(hostname = 'shop.example.com', 365, hostname = 'www.example.com', 30, 90))",
+		},
+		{
+			name:      "only hostnames",
+			retention: types.Retention{Hostnames: map[string]int{"www.example.com": 30}},
+			want:      "addDays(event_date, multiIf(hostname = 'www.example.com', 30, 0)) WHERE hostname IN ('www.example.com')",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, retentionTTL(tt.retention))
+		})
+	}
+}
+
+func Test_normalizeTTL(t *testing.T) {
+	require.Equal(
+		t,
+		normalizeTTL("addDays(event_date, 90)"),
+		normalizeTTL("addDays(event_date, 90) DELETE"),
+	)
+}
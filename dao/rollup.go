@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	baseRollupTableName = "webperf_rum_rollup_hourly"
+	baseRollupViewName  = "webperf_rum_rollup_hourly_mv"
+	backfillChunk       = 24 * time.Hour
+)
+
+// rollupSelect aggregates the page views of the events table by hostname, url group and hour
+// The url group is the url path with the numeric segments replaced by :id, for example /product/:id/reviews
+// %[1]s is the events table and %[2]s is the additional WHERE condition
+const rollupSelect = `SELECT
+    toStartOfHour(created_at) AS event_hour,
+    hostname,
+    arrayStringConcat(arrayMap(s -> if(match(s, '^[0-9]+$'), ':id', s), splitByChar('/', path(url))), '/') AS url_group,
+    device_type,
+    geo_country_code,
+    count() AS page_views,
+    quantilesState(0.5, 0.75, 0.95)(largest_contentful_paint) AS largest_contentful_paint,
+    quantilesState(0.5, 0.75, 0.95)(first_contentful_paint) AS first_contentful_paint,
+    quantilesState(0.5, 0.75, 0.95)(first_byte_duration) AS first_byte_duration,
+    quantilesState(0.5, 0.75, 0.95)(cumulative_layout_shift) AS cumulative_layout_shift,
+    quantilesState(0.5, 0.75, 0.95)(interaction_to_next_paint) AS interaction_to_next_paint
+FROM %[1]s
+WHERE event_type IN ('visit_page', 'spa_hard', 'spa')%[2]s
+GROUP BY event_hour, hostname, url_group, device_type, geo_country_code`
+
+// EnableRollup creates the materialized view populating the hourly rollup table when enabled and drops it otherwise
+// The existing hostname tenants are changed too, the new hostname tenants are changed when they are used first time
+func (p *DAO) EnableRollup(enabled bool) error {
+	p.tenantsMu.Lock()
+	p.rollupEnabled = &enabled
+	p.tenantsMu.Unlock()
+	tenants, err := p.existingTenants()
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		if err := p.enableRollup(t, enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *DAO) enableRollup(t tenant, enabled bool) error {
+	view := t.table(baseRollupViewName)
+	var query string
+	if enabled {
+		query = fmt.Sprintf(
+			"CREATE MATERIALIZED VIEW IF NOT EXISTS %s%s TO %s AS %s",
+			view,
+			p.onCluster(),
+			p.localTable(t, baseRollupTableName),
+			fmt.Sprintf(rollupSelect, p.localTable(t, baseTableName), ""),
+		)
+	} else {
+		query = fmt.Sprintf("DROP VIEW IF EXISTS %s%s", view, p.onCluster())
+	}
+	if err := p.conn.Exec(context.Background(), query); err != nil {
+		return fmt.Errorf("enable rollup view[%v] enabled[%v] err[%w]", view, enabled, err)
+	}
+	return nil
+}
+
+// BackfillRollup replaces the hourly rollup rows between from and to with the aggregated events
+// The period is processed by days, from and to are truncated to hours
+func (p *DAO) BackfillRollup(from, to time.Time) error {
+	from = from.Truncate(time.Hour)
+	to = to.Truncate(time.Hour)
+	if !from.Before(to) {
+		return fmt.Errorf("invalid backfill period from[%v] to[%v]", from, to)
+	}
+	tenants, err := p.existingTenants()
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		for start := from; start.Before(to); start = start.Add(backfillChunk) {
+			end := start.Add(backfillChunk)
+			if end.After(to) {
+				end = to
+			}
+			if err := p.backfillRollupChunk(t, start, end); err != nil {
+				return err
+			}
+			log.Printf("backfilled rollup table[%v] from[%v] to[%v]", t.table(baseRollupTableName), start, end)
+		}
+	}
+	return nil
+}
+
+func (p *DAO) backfillRollupChunk(t tenant, from, to time.Time) error {
+	ctx := context.Background()
+	deleteQuery := fmt.Sprintf(
+		"DELETE FROM %s%s WHERE event_hour >= toDateTime(%d) AND event_hour < toDateTime(%d)",
+		p.localTable(t, baseRollupTableName),
+		p.onCluster(),
+		from.Unix(),
+		to.Unix(),
+	)
+	if err := p.conn.Exec(ctx, deleteQuery); err != nil {
+		return fmt.Errorf("delete rollup from[%v] to[%v] err[%w]", from, to, err)
+	}
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s %s",
+		t.table(baseRollupTableName),
+		fmt.Sprintf(
+			rollupSelect,
+			t.table(baseTableName),
+			fmt.Sprintf(" AND created_at >= toDateTime(%d) AND created_at < toDateTime(%d)", from.Unix(), to.Unix()),
+		),
+	)
+	if err := p.conn.Exec(ctx, insertQuery); err != nil {
+		return fmt.Errorf("insert rollup from[%v] to[%v] err[%w]", from, to, err)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package dao
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// registeredHostnamesTTL is the period of reloading the hostnames registered by the other processes
+	registeredHostnamesTTL = time.Minute
+	// tenantHashSize is the number of the hostname hash bytes in the tenant name
+	tenantHashSize = 4
+)
+
+// DatabaseStrategy defines the database of the hostname tables
+type DatabaseStrategy string
+
+const (
+	// AllInOneDB stores all hostnames in the configured database
+	AllInOneDB DatabaseStrategy = "all_in_one_db"
+	// DBPerHostname stores every hostname in own database <database name>_<hostname>_<hash>
+	DBPerHostname DatabaseStrategy = "db_per_hostname"
+)
+
+// TableStrategy defines the table prefix of the hostname tables
+type TableStrategy string
+
+const (
+	// AllInOneTable stores all hostnames in the prefixed tables
+	AllInOneTable TableStrategy = "all_in_one_table"
+	// TablePerHostname stores every hostname in own tables <prefix><hostname>_<hash>_<table>
+	TablePerHostname TableStrategy = "table_per_hostname"
+)
+
+// tenantTables are the tables routed by the hostname, the other tables are shared by all hostnames
+// nolint: gochecknoglobals
+var tenantTables = map[string]struct{}{
+	baseTableName:       {},
+	baseXhrTableName:    {},
+	baseErrorsTableName: {},
+}
+
+// tenantMigrationTables are the tables of the tenant migrations, the tenant tables with the sessions and the rollup views
+// nolint: gochecknoglobals
+var tenantMigrationTables = map[string]struct{}{
+	baseTableName:         {},
+	baseXhrTableName:      {},
+	baseErrorsTableName:   {},
+	baseSessionsTableName: {},
+	baseRollupTableName:   {},
+}
+
+// ITenantMigrator applies the migrations of the hostname tables
+type ITenantMigrator interface {
+	MigrateTenant(database, prefix string) error
+}
+
+// Strategy routes the hostname events into the databases and the tables
+type Strategy struct {
+	database     DatabaseStrategy
+	table        TableStrategy
+	databaseName string
+}
+
+// NewStrategy creates the persistance strategy, the empty values are all in one
+func NewStrategy(database, table, databaseName string) (Strategy, error) {
+	result := Strategy{
+		database:     AllInOneDB,
+		table:        AllInOneTable,
+		databaseName: databaseName,
+	}
+	switch DatabaseStrategy(database) {
+	case "", AllInOneDB:
+	case DBPerHostname:
+		result.database = DBPerHostname
+	default:
+		return result, fmt.Errorf("unsupported database strategy[%v]", database)
+	}
+	switch TableStrategy(table) {
+	case "", AllInOneTable:
+	case TablePerHostname:
+		result.table = TablePerHostname
+	default:
+		return result, fmt.Errorf("unsupported table strategy[%v]", table)
+	}
+	return result, nil
+}
+
+// AllInOne checks if all hostnames are stored in the same tables
+func (s Strategy) AllInOne() bool {
+	return s.database == AllInOneDB && s.table == AllInOneTable
+}
+
+// Location gets the database and the table prefix of the hostname tables, the empty database is the configured database
+func (s Strategy) Location(prefix, hostname string) (string, string) {
+	t := s.tenant(prefix, hostname)
+	return t.database, t.prefix
+}
+
+func (s Strategy) tenant(prefix, hostname string) tenant {
+	result := tenant{prefix: prefix}
+	name := tenantName(hostname)
+	if name == "" {
+		return result
+	}
+	if s.database == DBPerHostname {
+		result.database = s.databaseName + "_" + name
+	}
+	if s.table == TablePerHostname {
+		result.prefix = prefix + name + "_"
+	}
+	return result
+}
+
+// tenantName converts the hostname into identifier, the characters except letters and digits are replaced by _
+// The hash suffix of the hostname keeps the hostnames which differ only in the replaced characters apart
+func tenantName(hostname string) string {
+	hostname = strings.ToLower(hostname)
+	if hostname == "" {
+		return ""
+	}
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, hostname)
+	hash := sha256.Sum256([]byte(hostname))
+	return name + "_" + hex.EncodeToString(hash[:tenantHashSize])
+}
+
+// tenant is the database and the table prefix of the hostname tables
+type tenant struct {
+	// database is empty for the connection database
+	database string
+	prefix   string
+}
+
+// table returns the table name with prefix qualified by the tenant database
+func (t tenant) table(name string) string {
+	if t.database == "" {
+		return t.prefix + name
+	}
+	return t.database + "." + t.prefix + name
+}
+
+// databaseCondition filters the system tables by the tenant database
+func (t tenant) databaseCondition() string {
+	if t.database == "" {
+		return "database = currentDatabase()"
+	}
+	return fmt.Sprintf("database = '%s'", t.database)
+}
+
+// WithStrategy routes the events of the registered hostnames, the migrations of the new hostname are applied on the first use
+// The events of the unregistered hostnames are stored in the default tables. The nil migrator does not apply the migrations
+func WithStrategy(strategy Strategy, migrator ITenantMigrator) func(*DAO) {
+	return func(p *DAO) {
+		p.strategy = strategy
+		p.migrator = migrator
+	}
+}
+
+func (p *DAO) defaultTenant() tenant {
+	return tenant{prefix: p.prefix}
+}
+
+// hostnameTenant gets the tenant of the hostname, the tenant tables are migrated on the first use
+func (p *DAO) hostnameTenant(hostname string) (tenant, error) {
+	result, err := p.registeredTenant(hostname)
+	if err != nil || result == p.defaultTenant() {
+		return result, err
+	}
+	p.tenantsMu.Lock()
+	defer p.tenantsMu.Unlock()
+	if _, ok := p.tenants[result]; ok {
+		return result, nil
+	}
+	if err := p.setupTenant(result); err != nil {
+		return result, fmt.Errorf("setup tenant hostname[%v] err[%w]", hostname, err)
+	}
+	p.tenants[result] = struct{}{}
+	log.Printf("tenant hostname[%v] database[%v] prefix[%v] is ready", hostname, result.database, result.prefix)
+	return result, nil
+}
+
+// registeredTenant gets the tenant of the registered hostname, the other hostnames use the default tenant
+// The hostname comes from the beacon, so only the registered hostnames create the databases and the tables
+func (p *DAO) registeredTenant(hostname string) (tenant, error) {
+	result := p.strategy.tenant(p.prefix, hostname)
+	if result == p.defaultTenant() {
+		return result, nil
+	}
+	registered, err := p.isRegistered(hostname)
+	if err != nil {
+		return p.defaultTenant(), err
+	}
+	if !registered {
+		return p.defaultTenant(), nil
+	}
+	return result, nil
+}
+
+// isRegistered checks if the hostname is in the owner hostnames table
+// The registered hostnames are cached and reloaded after registeredHostnamesTTL
+func (p *DAO) isRegistered(hostname string) (bool, error) {
+	p.registeredMu.Lock()
+	defer p.registeredMu.Unlock()
+	if p.registered == nil || time.Since(p.registeredAt) > registeredHostnamesTTL {
+		registered, err := p.ownerHostnames()
+		if err != nil {
+			return false, err
+		}
+		p.registered = registered
+		p.registeredAt = time.Now()
+	}
+	_, ok := p.registered[hostname]
+	return ok, nil
+}
+
+// addRegistered adds the hostname registered by this process to the cache
+func (p *DAO) addRegistered(hostname string) {
+	p.registeredMu.Lock()
+	defer p.registeredMu.Unlock()
+	if p.registered != nil {
+		p.registered[hostname] = struct{}{}
+	}
+}
+
+// resetRegistered reloads the registered hostnames on the next use
+func (p *DAO) resetRegistered() {
+	p.registeredMu.Lock()
+	defer p.registeredMu.Unlock()
+	p.registered = nil
+}
+
+func (p *DAO) ownerHostnames() (map[string]struct{}, error) {
+	query := fmt.Sprintf("SELECT DISTINCT hostname FROM %s%s FINAL", p.prefix, baseOwnerHostsTableName)
+	rows, err := p.conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("get owner hostnames failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := map[string]struct{}{}
+	for rows.Next() {
+		var hostname string
+		if err := rows.Scan(&hostname); err != nil {
+			return nil, fmt.Errorf("get owner hostnames failed: %w", err)
+		}
+		result[hostname] = struct{}{}
+	}
+	return result, rows.Err()
+}
+
+// setupTenant applies the migrations, the rollup and the retention of the tenant tables
+// The migrations are skipped without migrator, then the tenant tables are created separately
+func (p *DAO) setupTenant(t tenant) error {
+	if p.migrator != nil {
+		if err := p.migrator.MigrateTenant(t.database, t.prefix); err != nil {
+			return err
+		}
+	}
+	if p.rollupEnabled != nil {
+		if err := p.enableRollup(t, *p.rollupEnabled); err != nil {
+			return err
+		}
+	}
+	if p.retention != nil {
+		return p.applyRetention(t, *p.retention)
+	}
+	return nil
+}
+
+// existingTenants gets the default tenant and the tenants of the seen hostnames with the existing tables
+// The tenants are not set up, so the read paths do not run the migrations
+func (p *DAO) existingTenants() ([]tenant, error) {
+	result := []tenant{p.defaultTenant()}
+	if p.strategy.AllInOne() {
+		return result, nil
+	}
+	hostnames, err := p.hostnames()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := p.existingTenantSet()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[tenant]struct{}{result[0]: {}}
+	for _, hostname := range hostnames {
+		t := p.strategy.tenant(p.prefix, hostname)
+		if _, ok := existing[t]; !ok {
+			continue
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// existingTenant gets the tenant of the hostname without the setup, the hostname without the tenant tables uses the default tenant
+func (p *DAO) existingTenant(hostname string) (tenant, error) {
+	result := p.strategy.tenant(p.prefix, hostname)
+	if result == p.defaultTenant() {
+		return result, nil
+	}
+	existing, err := p.existingTenantSet()
+	if err != nil {
+		return result, err
+	}
+	if _, ok := existing[result]; !ok {
+		return p.defaultTenant(), nil
+	}
+	return result, nil
+}
+
+// existingTenantSet gets the tenants with the events table in all databases
+func (p *DAO) existingTenantSet() (map[tenant]struct{}, error) {
+	suffix := p.localName(tenant{}, baseTableName)
+	query := "SELECT if(database = currentDatabase(), '', database), name FROM system.tables WHERE endsWith(name, ?)"
+	rows, err := p.conn.Query(context.Background(), query, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant tables failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := map[tenant]struct{}{}
+	for rows.Next() {
+		var database, name string
+		if err := rows.Scan(&database, &name); err != nil {
+			return nil, fmt.Errorf("get tenant tables failed: %w", err)
+		}
+		result[tenant{database: database, prefix: strings.TrimSuffix(name, suffix)}] = struct{}{}
+	}
+	return result, rows.Err()
+}
+
+func (p *DAO) hostnames() ([]string, error) {
+	query := fmt.Sprintf("SELECT DISTINCT hostname FROM %s%s ORDER BY hostname", p.prefix, baseHostsTableName)
+	rows, err := p.conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("get hostnames failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var hostname string
+		if err := rows.Scan(&hostname); err != nil {
+			return nil, fmt.Errorf("get hostnames failed: %w", err)
+		}
+		result = append(result, hostname)
+	}
+	return result, rows.Err()
+}
+
+// rowsHostname gets the hostname of the first JSONEachRow row, the rows of one insert have the same hostname
+func rowsHostname(rows []byte) string {
+	line, _, _ := strings.Cut(string(rows), "\n")
+	var row struct {
+		Hostname string `json:"hostname"`
+	}
+	if err := json.Unmarshal([]byte(line), &row); err != nil {
+		return ""
+	}
+	return row.Hostname
+}
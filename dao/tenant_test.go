@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		database string
+		table    string
+		wantErr  bool
+		allInOne bool
+	}{
+		{
+			name:     "empty",
+			allInOne: true,
+		},
+		{
+			name:     "all in one",
+			database: "all_in_one_db",
+			table:    "all_in_one_table",
+			allInOne: true,
+		},
+		{
+			name:     "db per hostname",
+			database: "db_per_hostname",
+			table:    "all_in_one_table",
+		},
+		{
+			name:     "table per hostname",
+			database: "all_in_one_db",
+			table:    "table_per_hostname",
+		},
+		{
+			name:     "unsupported database",
+			database: "db_per_customer",
+			wantErr:  true,
+		},
+		{
+			name:    "unsupported table",
+			table:   "table_per_customer",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewStrategy(tt.database, tt.table, "default")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.allInOne, got.AllInOne())
+		})
+	}
+}
+
+func TestStrategy_tenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		database  DatabaseStrategy
+		table     TableStrategy
+		hostname  string
+		wantTable string
+	}{
+		{
+			name:      "all in one",
+			database:  AllInOneDB,
+			table:     AllInOneTable,
+			hostname:  "www.example.com",
+			wantTable: "prefix_webperf_rum_events",
+		},
+		{
+			name:      "db per hostname",
+			database:  DBPerHostname,
+			table:     AllInOneTable,
+			hostname:  "www.example.com",
+			wantTable: "default_www_example_com_80fc0fb9.prefix_webperf_rum_events",
+		},
+		{
+			name:      "table per hostname",
+			database:  AllInOneDB,
+			table:     TablePerHostname,
+			hostname:  "Shop-1.Example.com",
+			wantTable: "prefix_shop_1_example_com_1547b8b8_webperf_rum_events",
+		},
+		{
+			name:      "db and table per hostname",
+			database:  DBPerHostname,
+			table:     TablePerHostname,
+			hostname:  "www.example.com",
+			wantTable: "default_www_example_com_80fc0fb9.prefix_www_example_com_80fc0fb9_webperf_rum_events",
+		},
+		{
+			name:      "empty hostname",
+			database:  DBPerHostname,
+			table:     TablePerHostname,
+			wantTable: "prefix_webperf_rum_events",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Strategy{database: tt.database, table: tt.table, databaseName: "default"}
+			require.Equal(t, tt.wantTable, s.tenant("prefix_", tt.hostname).table(baseTableName))
+		})
+	}
+}
+
+func Test_tenantName(t *testing.T) {
+	require.Equal(t, "www_example_com_80fc0fb9", tenantName("WWW.Example.com"))
+	require.NotEqual(t, tenantName("a-b.com"), tenantName("a_b.com"))
+	require.Equal(t, "", tenantName(""))
+}
+
+func Test_rowsHostname(t *testing.T) {
+	require.Equal(t, "www.example.com", rowsHostname([]byte("{\"hostname\":\"www.example.com\"}\n{\"hostname\":\"www.example.com\"}\n")))
+	require.Equal(t, "", rowsHostname([]byte("invalid")))
+}
+
+func TestMigrationDAO_Tenant(t *testing.T) {
+	p := NewMigrationDAO(server{db: "default"}, auth{}, nil, &opts{prefix: "prefix_"})
+	require.True(t, p.migratesFile("00000000000003_webperf_rum_hostnames.up.sql"))
+
+	tenant := p.Tenant("default_www_example_com_80fc0fb9", "")
+	require.Equal(t, "default.default_www_example_com_80fc0fb9_", tenant.statePrefix)
+	require.True(t, tenant.migratesFile("00000000000001_webperf_rum_events.up.sql"))
+	require.True(t, tenant.migratesFile("00000000000012_webperf_rum_xhr.down.sql"))
+	require.True(t, tenant.migratesFile("00000000000016_webperf_rum_sessions.up.sql"))
+	require.True(t, tenant.migratesFile("00000000000017_webperf_rum_rollup_hourly.up.sql"))
+	require.False(t, tenant.migratesFile("00000000000003_webperf_rum_hostnames.up.sql"))
+	require.False(t, tenant.migratesFile("00000000000015_webperf_rum_dead_letters.up.sql"))
+	require.False(t, tenant.migratesFile("00000000000018_webperf_rum_own_hostnames.up.sql"))
+
+	require.Equal(t, "www_example_com_80fc0fb9_", p.Tenant("", "www_example_com_80fc0fb9_").statePrefix)
+}
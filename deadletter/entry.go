@@ -0,0 +1,39 @@
+package deadletter
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const timeFormat = "2006-01-02 15:04:05.000"
+
+// Entry contains the rows which failed to be inserted with the original request parameters
+type Entry struct {
+	ID        string `json:"id"`
+	Failed_At string `json:"failed_at"`
+	// Table_Name is the table name without prefix
+	Table_Name string `json:"table_name"`
+	// Data contains the JSONEachRow rows
+	Data       string            `json:"data"`
+	Error      string            `json:"error"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// NewEntry creates dead letter entry
+// nolint: revive
+func NewEntry(table string, rows []byte, parameters url.Values, err error, failedAt time.Time) Entry {
+	flatten := make(map[string]string, len(parameters))
+	for name := range parameters {
+		flatten[name] = parameters.Get(name)
+	}
+	return Entry{
+		ID:         uuid.NewString(),
+		Failed_At:  failedAt.UTC().Format(timeFormat),
+		Table_Name: table,
+		Data:       string(rows),
+		Error:      err.Error(),
+		Parameters: flatten,
+	}
+}
@@ -0,0 +1,41 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const (
+	// sessionParameter is the beacon parameter of the session id
+	sessionParameter = "rt.si"
+	// pageParameter is the beacon parameter of the page id
+	pageParameter = "pid"
+)
+
+// EraseRows removes the erased rows from the entry and returns the number of the removed rows
+// All rows of the erased beacon are removed, the entry without rows should be deleted
+func EraseRows(entry Entry, matcher types.ErasureMatcher) (Entry, int) {
+	rows := strings.Split(strings.TrimSuffix(entry.Data, "\n"), "\n")
+	if matcher.Matches(entry.Parameters[sessionParameter], entry.Parameters[pageParameter]) {
+		entry.Data = ""
+		return entry, len(rows)
+	}
+	kept := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var item struct {
+			SessionID string `json:"session_id"`
+			PageID    string `json:"page_id"`
+		}
+		if err := json.Unmarshal([]byte(row), &item); err == nil && matcher.Matches(item.SessionID, item.PageID) {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	removed := len(rows) - len(kept)
+	if removed > 0 {
+		entry.Data = strings.Join(kept, "\n")
+	}
+	return entry, removed
+}
@@ -0,0 +1,200 @@
+package deadletter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const (
+	resubmitSuffix = ".resubmit"
+	lockSuffix     = ".lock"
+	maxLineSize    = 16 * 1024 * 1024
+)
+
+// FileStore stores the dead letter entries in NDJSON file
+// The server and the commands share the file, so the changes are guarded by the file locks of all processes.
+// The file lock guards the file, the resubmit lock guards the file of the running resubmit
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates dead letter file storage. The directory is created when it does not exist
+func NewFileStore(path string) (*FileStore, error) {
+	directory := filepath.Dir(path)
+	if err := os.MkdirAll(directory, os.ModeDir.Perm()); err != nil {
+		return nil, fmt.Errorf("cannot create directory[%v] err[%w]", directory, err)
+	}
+	return &FileStore{path: path}, nil
+}
+
+// Store appends the entry to the file
+func (s *FileStore) Store(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return withLock(s.path+lockSuffix, func() error {
+		return appendLines(s.path, append(data, '\n'))
+	})
+}
+
+// List reads the first entries from the file
+func (s *FileStore) List(limit int) ([]Entry, error) {
+	var result []Entry
+	err := withLock(s.path+lockSuffix, func() error {
+		var err error
+		result, err = readEntries(s.path, limit)
+		return err
+	})
+	return result, err
+}
+
+// Resubmit moves the file aside, submits the entries and appends the failed entries back
+// The server can store new entries during the resubmit, the erasure waits until the resubmit ends
+func (s *FileStore) Resubmit(submit func(entry Entry) error) (int, error) {
+	resubmitPath := s.path + resubmitSuffix
+	submitted := 0
+	err := withLock(resubmitPath+lockSuffix, func() error {
+		if err := withLock(s.path+lockSuffix, func() error {
+			return s.moveAside(resubmitPath)
+		}); err != nil {
+			return err
+		}
+		entries, err := readEntries(resubmitPath, 0)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := submit(entry); err != nil {
+				log.Printf("resubmit dead letter id[%v] err[%v]", entry.ID, err)
+				if err := s.Store(entry); err != nil {
+					return fmt.Errorf("cannot store dead letter id[%v] err[%w]", entry.ID, err)
+				}
+				continue
+			}
+			submitted++
+		}
+		return os.Remove(resubmitPath)
+	})
+	return submitted, err
+}
+
+// Erase rewrites the file and the file of the interrupted resubmit without the erased rows
+// It waits until the running resubmit ends, so the resubmit does not insert the erased rows
+func (s *FileStore) Erase(request types.ErasureRequest) (int, error) {
+	matcher := request.Matcher()
+	removed := 0
+	err := withLock(s.path+resubmitSuffix+lockSuffix, func() error {
+		return withLock(s.path+lockSuffix, func() error {
+			for _, path := range []string{s.path, s.path + resubmitSuffix} {
+				count, err := eraseFile(path, matcher)
+				removed += count
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	return removed, err
+}
+
+// withLock calls fn while it holds the lock file, the resubmit lock is taken before the file lock
+func withLock(lockPath string, fn func() error) error {
+	unlock, err := lockFile(lockPath)
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if unlockErr := unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// eraseFile writes the entries without the erased rows to temp file and renames it, so the entries are not lost when the rewrite fails
+func eraseFile(path string, matcher types.ErasureMatcher) (int, error) {
+	entries, err := readEntries(path, 0)
+	if err != nil {
+		return 0, err
+	}
+	var data []byte
+	removed := 0
+	for _, entry := range entries {
+		entry, count := EraseRows(entry, matcher)
+		removed += count
+		if entry.Data == "" {
+			continue
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return 0, err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0o600); err != nil {
+		return 0, fmt.Errorf("cannot write file[%v] err[%w]", tempPath, err)
+	}
+	return removed, os.Rename(tempPath, path)
+}
+
+// moveAside renames the file unless the previous resubmit was interrupted
+func (s *FileStore) moveAside(resubmitPath string) error {
+	if _, err := os.Stat(resubmitPath); err == nil {
+		return nil
+	}
+	err := os.Rename(s.path, resubmitPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.WriteFile(resubmitPath, nil, 0o600)
+	}
+	return err
+}
+
+func appendLines(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open file[%v] err[%w]", path, err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func readEntries(path string, limit int) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file[%v] err[%w]", path, err)
+	}
+	defer f.Close()
+
+	var result []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() && (limit <= 0 || len(result) < limit) {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return result, fmt.Errorf("invalid dead letter file[%v] err[%w]", path, err)
+		}
+		result = append(result, entry)
+	}
+	return result, scanner.Err()
+}
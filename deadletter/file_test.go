@@ -0,0 +1,130 @@
+package deadletter
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "dead_letter", "dead_letter.ndjson"))
+	require.NoError(t, err)
+
+	entries, err := store.List(0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	failedAt := time.Date(2022, 8, 27, 5, 53, 0, 0, time.UTC)
+	first := NewEntry("webperf_rum_events", []byte(`{"hostname":"www.example.com"}`), url.Values{"u": {"https://www.example.com/"}}, errors.New("connection refused"), failedAt)
+	second := NewEntry("webperf_rum_xhr", []byte(`{"hostname":"www.example.org"}`), url.Values{"u": {"https://www.example.org/"}}, errors.New("connection refused"), failedAt)
+	require.NoError(t, store.Store(first))
+	require.NoError(t, store.Store(second))
+
+	entries, err = store.List(0)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{first, second}, entries)
+	require.Equal(t, "2022-08-27 05:53:00.000", entries[0].Failed_At)
+	require.Equal(t, map[string]string{"u": "https://www.example.com/"}, entries[0].Parameters)
+
+	entries, err = store.List(1)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{first}, entries)
+
+	submitted, err := store.Resubmit(func(entry Entry) error {
+		if entry.Table_Name == "webperf_rum_xhr" {
+			return errors.New("still failing")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, submitted)
+
+	entries, err = store.List(0)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{second}, entries)
+
+	submitted, err = store.Resubmit(func(_ Entry) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, submitted)
+
+	entries, err = store.List(0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestFileStore_Erase(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "dead_letter.ndjson"))
+	require.NoError(t, err)
+	failedAt := time.Date(2022, 8, 27, 5, 53, 0, 0, time.UTC)
+	errorRows := []byte(`{"session_id":"session1","message":"a"}` + "\n" + `{"session_id":"session2","message":"b"}` + "\n")
+	beacon := NewEntry("webperf_rum_events", []byte(`{"hostname":"www.example.com"}`), url.Values{"rt.si": {"session1"}}, errors.New("connection refused"), failedAt)
+	errorsEntry := NewEntry("webperf_rum_errors", errorRows, url.Values{}, errors.New("connection refused"), failedAt)
+	kept := NewEntry("webperf_rum_events", []byte(`{"session_id":"session3"}`), url.Values{"rt.si": {"session3"}}, errors.New("connection refused"), failedAt)
+	page := NewEntry("webperf_rum_xhr", []byte(`{"session_id":"session3","page_id":"page3"}`), url.Values{}, errors.New("connection refused"), failedAt)
+	require.NoError(t, store.Store(beacon))
+	require.NoError(t, store.Store(errorsEntry))
+	require.NoError(t, store.Store(kept))
+	require.NoError(t, store.Store(page))
+
+	removed, err := store.Erase(types.ErasureRequest{SessionIDs: []string{"session1"}})
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+
+	entries, err := store.List(0)
+	require.NoError(t, err)
+	errorsEntry.Data = `{"session_id":"session2","message":"b"}`
+	require.Equal(t, []Entry{errorsEntry, kept, page}, entries)
+
+	removed, err = store.Erase(types.ErasureRequest{SessionIDs: []string{"session4"}})
+	require.NoError(t, err)
+	require.Equal(t, 0, removed)
+
+	removed, err = store.Erase(types.ErasureRequest{PageIDs: []string{"page3"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+}
+
+func TestFileStore_ResubmitLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead_letter.ndjson")
+	// the stores share the file like the server and the commands
+	server, err := NewFileStore(path)
+	require.NoError(t, err)
+	command, err := NewFileStore(path)
+	require.NoError(t, err)
+	failedAt := time.Date(2022, 8, 27, 5, 53, 0, 0, time.UTC)
+	erased := NewEntry("webperf_rum_events", []byte(`{"session_id":"session1"}`), url.Values{"rt.si": {"session1"}}, errors.New("connection refused"), failedAt)
+	stored := NewEntry("webperf_rum_events", []byte(`{"session_id":"session2"}`), url.Values{"rt.si": {"session2"}}, errors.New("connection refused"), failedAt)
+	require.NoError(t, server.Store(erased))
+
+	erasedRows := make(chan int, 1)
+	submitted, err := command.Resubmit(func(entry Entry) error {
+		// the server stores the new entries during the resubmit
+		require.NoError(t, server.Store(stored))
+		go func() {
+			removed, err := server.Erase(types.ErasureRequest{SessionIDs: []string{"session1"}})
+			require.NoError(t, err)
+			erasedRows <- removed
+		}()
+		select {
+		case <-erasedRows:
+			t.Error("erase did not wait for the resubmit")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return errors.New("connection refused")
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, submitted)
+
+	// the erasure removes the entry stored back by the failed resubmit
+	require.Equal(t, 1, <-erasedRows)
+	entries, err := command.List(0)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{stored}, entries)
+}
@@ -0,0 +1,30 @@
+package deadletter
+
+import "github.com/basicrum/front_basicrum_go/types"
+
+//go:generate mockgen -source=${GOFILE} -destination=mocks/${GOFILE} -package=deadlettermocks
+
+// IStore is dead letter storage of the rows which failed to be inserted
+type IStore interface {
+	// Store saves the failed rows
+	Store(entry Entry) error
+	// List returns the first stored entries. Zero limit returns all the entries
+	List(limit int) ([]Entry, error)
+	// Resubmit calls submit for all the entries and removes the submitted entries
+	// The entries are kept when submit returns error
+	Resubmit(submit func(entry Entry) error) (int, error)
+	// Erase removes the rows of the erased sessions and page views and returns the number of the removed rows
+	Erase(request types.ErasureRequest) (int, error)
+}
+
+// Storage is the dead letter storage type
+type Storage string
+
+const (
+	// StorageNone disables the dead letter storage
+	StorageNone Storage = "none"
+	// StorageFile stores the dead letters in NDJSON file
+	StorageFile Storage = "file"
+	// StorageClickHouse stores the dead letters in webperf_rum_dead_letters table
+	StorageClickHouse Storage = "clickhouse"
+)
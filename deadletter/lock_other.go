@@ -0,0 +1,20 @@
+//go:build !unix
+
+package deadletter
+
+import "sync"
+
+// locks contains the mutex of each lock file path
+// nolint: gochecknoglobals
+var locks sync.Map
+
+// lockFile locks the path in the process only, the systems without flock do not share the file between the processes
+func lockFile(path string) (func() error, error) {
+	value, _ := locks.LoadOrStore(path, &sync.Mutex{})
+	lock := value.(*sync.Mutex)
+	lock.Lock()
+	return func() error {
+		lock.Unlock()
+		return nil
+	}, nil
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package deadletter
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes the exclusive flock of the file, so the other processes wait for the unlock
+// The lock is released when the file is closed, also when the process exits
+func lockFile(path string) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open lock file[%v] err[%w]", path, err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("cannot lock file[%v] err[%w]", path, err)
+	}
+	return f.Close, nil
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package deadlettermocks is a generated GoMock package.
+package deadlettermocks
+
+import (
+	reflect "reflect"
+
+	deadletter "github.com/basicrum/front_basicrum_go/deadletter"
+	types "github.com/basicrum/front_basicrum_go/types"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIStore is a mock of IStore interface.
+type MockIStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStoreMockRecorder
+}
+
+// MockIStoreMockRecorder is the mock recorder for MockIStore.
+type MockIStoreMockRecorder struct {
+	mock *MockIStore
+}
+
+// NewMockIStore creates a new mock instance.
+func NewMockIStore(ctrl *gomock.Controller) *MockIStore {
+	mock := &MockIStore{ctrl: ctrl}
+	mock.recorder = &MockIStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStore) EXPECT() *MockIStoreMockRecorder {
+	return m.recorder
+}
+
+// Erase mocks base method.
+func (m *MockIStore) Erase(request types.ErasureRequest) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", request)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockIStoreMockRecorder) Erase(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockIStore)(nil).Erase), request)
+}
+
+// List mocks base method.
+func (m *MockIStore) List(limit int) ([]deadletter.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", limit)
+	ret0, _ := ret[0].([]deadletter.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIStoreMockRecorder) List(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIStore)(nil).List), limit)
+}
+
+// Resubmit mocks base method.
+func (m *MockIStore) Resubmit(submit func(deadletter.Entry) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resubmit", submit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resubmit indicates an expected call of Resubmit.
+func (mr *MockIStoreMockRecorder) Resubmit(submit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resubmit", reflect.TypeOf((*MockIStore)(nil).Resubmit), submit)
+}
+
+// Store mocks base method.
+func (m *MockIStore) Store(entry deadletter.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockIStoreMockRecorder) Store(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockIStore)(nil).Store), entry)
+}
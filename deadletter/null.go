@@ -0,0 +1,32 @@
+package deadletter
+
+import "github.com/basicrum/front_basicrum_go/types"
+
+// NullStore is disabled dead letter storage
+type NullStore struct {
+}
+
+// NewNullStore creates disabled dead letter storage
+func NewNullStore() *NullStore {
+	return &NullStore{}
+}
+
+// Store disabled implementation
+func (*NullStore) Store(_ Entry) error {
+	return nil
+}
+
+// List disabled implementation
+func (*NullStore) List(_ int) ([]Entry, error) {
+	return nil, nil
+}
+
+// Resubmit disabled implementation
+func (*NullStore) Resubmit(_ func(entry Entry) error) (int, error) {
+	return 0, nil
+}
+
+// Erase disabled implementation
+func (*NullStore) Erase(_ types.ErasureRequest) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,124 @@
+package erasure
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/basicrum/front_basicrum_go/backup"
+	"github.com/basicrum/front_basicrum_go/cache"
+	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/deadletter"
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const (
+	// maxJobs limits the number of the jobs kept in memory
+	maxJobs = 1000
+	// jobTTL is the time the finished jobs can be read
+	jobTTL = 24 * time.Hour
+)
+
+// Service erases the sessions and the page views from the database tables, the dead letters and the backup files
+type Service struct {
+	daoService dao.IDAO
+	deadLetter deadletter.IStore
+	// backupDirectory is the backup archive directory, it is empty when the backup is disabled
+	backupDirectory string
+	now             func() time.Time
+	// lock runs one erasure at a time, so the backup files and the dead letters are not rewritten concurrently
+	lock sync.Mutex
+	jobs *cache.LRU[string, types.ErasureJob]
+}
+
+// New creates erasure service
+func New(daoService dao.IDAO, deadLetter deadletter.IStore, backupDirectory string) (*Service, error) {
+	jobs, err := cache.NewLRU[string, types.ErasureJob](maxJobs, jobTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		daoService:      daoService,
+		deadLetter:      deadLetter,
+		backupDirectory: backupDirectory,
+		now:             time.Now,
+		jobs:            jobs,
+	}, nil
+}
+
+// Start validates the request and runs the erasure in background
+// The jobs are kept in memory, so the job of the restarted server is not found
+func (s *Service) Start(request types.ErasureRequest) (types.ErasureJob, error) {
+	if err := request.Validate(); err != nil {
+		return types.ErasureJob{}, err
+	}
+	job := types.ErasureJob{
+		ID:        uuid.NewString(),
+		State:     types.ErasureRunning,
+		Result:    types.ErasureResult{ErasureRequest: request},
+		StartedAt: s.now().UTC(),
+	}
+	s.jobs.Add(job.ID, job)
+	go s.run(job)
+	return job, nil
+}
+
+func (s *Service) run(job types.ErasureJob) {
+	result, err := s.Erase(job.Result.ErasureRequest)
+	finishedAt := s.now().UTC()
+	job.FinishedAt = &finishedAt
+	job.State = types.ErasureDone
+	if result != nil {
+		job.Result = *result
+	}
+	if err != nil {
+		log.Printf("erasure job[%v] err[%v]", job.ID, err)
+		job.State = types.ErasureFailed
+		job.Error = "cannot erase"
+	}
+	s.jobs.Add(job.ID, job)
+}
+
+// Job gets the erasure job by id
+func (s *Service) Job(id string) (types.ErasureJob, bool) {
+	return s.jobs.Get(id)
+}
+
+// Erase starts the database mutations, removes the dead letter rows and rewrites the backup files with the erased lines
+// The backup files are searched for the erased lines, so the repeated erasure removes the lines of the hour which was open before
+func (s *Service) Erase(request types.ErasureRequest) (*types.ErasureResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.daoService.Erase(request); err != nil {
+		return nil, err
+	}
+	result := &types.ErasureResult{ErasureRequest: request}
+	var err error
+	// the resubmitted dead letters would insert the erased rows again
+	result.DeadLetterRows, err = s.deadLetter.Erase(request)
+	if err != nil {
+		return result, fmt.Errorf("erase dead letters err[%w]", err)
+	}
+	if s.backupDirectory == "" {
+		return result, nil
+	}
+	result.BackupLines, err = backup.Erase(s.backupDirectory, request, s.now())
+	if err != nil {
+		return result, fmt.Errorf("erase backup err[%w]", err)
+	}
+	return result, nil
+}
+
+// Status gets the erasure progress of the database tables
+func (s *Service) Status(request types.ErasureRequest) ([]types.ErasureStatus, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	return s.daoService.ErasureStatus(request)
+}
@@ -0,0 +1,240 @@
+package erasure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	daomocks "github.com/basicrum/front_basicrum_go/dao/mocks"
+	deadlettermocks "github.com/basicrum/front_basicrum_go/deadletter/mocks"
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	erasedLine = `{"pid":"page1","rt.si":"session1","u":"https://www.example.com/"}` + "\n"
+	keptLine   = `{"pid":"page2","rt.si":"session2","u":"https://www.example.com/"}` + "\n"
+)
+
+func TestService_Erase(t *testing.T) {
+	type expects struct {
+		Erase           bool
+		EraseErr        error
+		EraseDeadLetter bool
+	}
+	tests := []struct {
+		name            string
+		request         types.ErasureRequest
+		backupEnabled   bool
+		expects         expects
+		want            *types.ErasureResult
+		wantErr         string
+		wantBackupLines string
+	}{
+		{
+			name:            "missing ids",
+			wantErr:         "session ids or page ids are required",
+			wantBackupLines: erasedLine + keptLine,
+		},
+		{
+			name:    "erase database without backup",
+			request: types.ErasureRequest{SessionIDs: []string{"session1"}},
+			expects: expects{
+				Erase:           true,
+				EraseDeadLetter: true,
+			},
+			want: &types.ErasureResult{
+				ErasureRequest: types.ErasureRequest{SessionIDs: []string{"session1"}},
+				DeadLetterRows: 2,
+			},
+			wantBackupLines: erasedLine + keptLine,
+		},
+		{
+			name:          "erase database and backup",
+			request:       types.ErasureRequest{SessionIDs: []string{"session1"}},
+			backupEnabled: true,
+			expects: expects{
+				Erase:           true,
+				EraseDeadLetter: true,
+			},
+			want: &types.ErasureResult{
+				ErasureRequest: types.ErasureRequest{SessionIDs: []string{"session1"}},
+				BackupLines:    1,
+				DeadLetterRows: 2,
+			},
+			wantBackupLines: keptLine,
+		},
+		{
+			name:          "erase page views",
+			request:       types.ErasureRequest{PageIDs: []string{"page1"}},
+			backupEnabled: true,
+			expects: expects{
+				Erase:           true,
+				EraseDeadLetter: true,
+			},
+			want: &types.ErasureResult{
+				ErasureRequest: types.ErasureRequest{PageIDs: []string{"page1"}},
+				BackupLines:    1,
+				DeadLetterRows: 2,
+			},
+			wantBackupLines: keptLine,
+		},
+		{
+			name:          "database error",
+			request:       types.ErasureRequest{SessionIDs: []string{"session1"}},
+			backupEnabled: true,
+			expects: expects{
+				Erase:    true,
+				EraseErr: errors.New("connection refused"),
+			},
+			wantErr:         "connection refused",
+			wantBackupLines: erasedLine + keptLine,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			backupDirectory := t.TempDir()
+			hourPath := filepath.Join(backupDirectory, "www_example_com", "2023-9-21", "0.json.lines")
+			require.NoError(t, os.MkdirAll(filepath.Dir(hourPath), os.ModePerm))
+			require.NoError(t, os.WriteFile(hourPath, []byte(erasedLine+keptLine), os.ModePerm))
+			daoService := daomocks.NewMockIDAO(ctrl)
+			if tt.expects.Erase {
+				daoService.EXPECT().Erase(tt.request).Return(tt.expects.EraseErr)
+			}
+			deadLetter := deadlettermocks.NewMockIStore(ctrl)
+			if tt.expects.EraseDeadLetter {
+				deadLetter.EXPECT().Erase(tt.request).Return(2, nil)
+			}
+			directory := ""
+			if tt.backupEnabled {
+				directory = backupDirectory
+			}
+			s, err := New(daoService, deadLetter, directory)
+			require.NoError(t, err)
+			s.now = func() time.Time { return time.Date(2023, 9, 22, 0, 0, 0, 0, time.UTC) }
+
+			got, err := s.Erase(tt.request)
+
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+			content, err := os.ReadFile(hourPath)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBackupLines, string(content))
+		})
+	}
+}
+
+func TestService_Erase_repeatAfterOpenHour(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given the backup appends to the hour file
+	backupDirectory := t.TempDir()
+	hour := time.Date(2023, 9, 21, 3, 0, 0, 0, time.UTC)
+	hourPath := filepath.Join(backupDirectory, "www_example_com", "2023-9-21", "3.json.lines")
+	require.NoError(t, os.MkdirAll(filepath.Dir(hourPath), os.ModePerm))
+	require.NoError(t, os.WriteFile(hourPath, []byte(erasedLine+keptLine), os.ModePerm))
+	request := types.ErasureRequest{SessionIDs: []string{"session1"}}
+	daoService := daomocks.NewMockIDAO(ctrl)
+	// the first erasure deletes the database rows, so the repeated erasure does not find them
+	daoService.EXPECT().Erase(request).Return(nil).Times(2)
+	deadLetter := deadlettermocks.NewMockIStore(ctrl)
+	deadLetter.EXPECT().Erase(request).Return(0, nil).Times(2)
+	s, err := New(daoService, deadLetter, backupDirectory)
+	require.NoError(t, err)
+	now := hour.Add(30 * time.Minute)
+	s.now = func() time.Time { return now }
+
+	// when
+	got, err := s.Erase(request)
+
+	// then the open hour is skipped
+	require.NoError(t, err)
+	require.Equal(t, 0, got.BackupLines)
+	content, err := os.ReadFile(hourPath)
+	require.NoError(t, err)
+	require.Equal(t, erasedLine+keptLine, string(content))
+
+	// when the hour ended
+	now = hour.Add(2 * time.Hour)
+	got, err = s.Erase(request)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1, got.BackupLines)
+	content, err = os.ReadFile(hourPath)
+	require.NoError(t, err)
+	require.Equal(t, keptLine, string(content))
+}
+
+func TestService_Start(t *testing.T) {
+	tests := []struct {
+		name      string
+		eraseErr  error
+		wantState types.ErasureState
+		wantError string
+	}{
+		{
+			name:      "done",
+			wantState: types.ErasureDone,
+		},
+		{
+			name:      "failed",
+			eraseErr:  errors.New("connection refused"),
+			wantState: types.ErasureFailed,
+			wantError: "cannot erase",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			request := types.ErasureRequest{SessionIDs: []string{"session1"}, PageIDs: []string{"page1"}}
+			daoService := daomocks.NewMockIDAO(ctrl)
+			daoService.EXPECT().Erase(request).Return(tt.eraseErr)
+			deadLetter := deadlettermocks.NewMockIStore(ctrl)
+			deadLetter.EXPECT().Erase(request).Return(1, nil).MaxTimes(1)
+			s, err := New(daoService, deadLetter, "")
+			require.NoError(t, err)
+
+			// when
+			started, err := s.Start(request)
+
+			// then
+			require.NoError(t, err)
+			require.NotEmpty(t, started.ID)
+			require.Equal(t, request, started.Result.ErasureRequest)
+			var job types.ErasureJob
+			require.Eventually(t, func() bool {
+				var ok bool
+				job, ok = s.Job(started.ID)
+				return ok && job.State != types.ErasureRunning
+			}, time.Second, time.Millisecond)
+			require.Equal(t, tt.wantState, job.State)
+			require.Equal(t, tt.wantError, job.Error)
+			require.NotNil(t, job.FinishedAt)
+		})
+	}
+}
+
+func TestService_Start_invalidRequest(t *testing.T) {
+	s, err := New(nil, nil, "")
+	require.NoError(t, err)
+
+	_, err = s.Start(types.ErasureRequest{PageIDs: []string{""}})
+
+	require.EqualError(t, err, "invalid page id[]")
+	_, ok := s.Job("unknown")
+	require.False(t, ok)
+}
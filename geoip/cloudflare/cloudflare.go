@@ -5,6 +5,13 @@ import (
 	"strings"
 )
 
+const (
+	// CountryHeader is the country code header added by Cloudflare
+	CountryHeader = "CF-IPCountry"
+	// CityHeader is the city header added by Cloudflare
+	CityHeader = "CF-IPCity"
+)
+
 // Service implement cloudflare geoip service
 type Service struct {
 }
@@ -17,8 +24,8 @@ func New() *Service {
 // CountryAndCity return country and city by http headers and remote ip address
 // nolint: revive
 func (s *Service) CountryAndCity(header http.Header, _ string) (string, string, error) {
-	country := cleanupHeaderValue(header.Get("CF-IPCountry"))
-	city := cleanupHeaderValue(header.Get("CF-IPCity"))
+	country := cleanupHeaderValue(header.Get(CountryHeader))
+	city := cleanupHeaderValue(header.Get(CityHeader))
 	return country, city, nil
 }
 
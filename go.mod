@@ -6,8 +6,8 @@ require (
 	github.com/ClickHouse/clickhouse-go/v2 v2.17.1
 	github.com/eapache/go-resiliency v1.5.0
 	github.com/golang/mock v1.6.0
+	github.com/hashicorp/golang-lru v1.0.2
 	github.com/martinlindhe/base36 v1.1.1
-	github.com/mileusna/useragent v1.3.4
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/rs/cors v1.10.1
 	github.com/stretchr/testify v1.8.4
@@ -21,7 +21,6 @@ require (
 require (
 	github.com/codemodus/kace v0.5.1 // indirect
 	github.com/fatih/color v1.16.0 // indirect
-	github.com/hashicorp/golang-lru v1.0.2 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
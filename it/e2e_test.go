@@ -35,9 +35,12 @@ func (s *e2eTestSuite) SetupTest() {
 		s.httpSender,
 	)
 
+	daoConnection, err := dao.Connection()
+	s.Assert().NoError(err)
 	conn, err := dao.NewConnection(
 		dao.Server(sConf.Database.Host, sConf.Database.Port, sConf.Database.DatabaseName),
 		dao.Auth(sConf.Database.Username, sConf.Database.Password),
+		daoConnection,
 	)
 	s.Assert().NoError(err)
 	s.dao = NewIntegrationDao(
@@ -100,6 +103,15 @@ func (s *e2eTestSuite) Test_EndToEnd_BeaconFieldsPersisted() {
 	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where mob_etype = '4g'"))
 	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where mob_dl = 10"))
 	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where mob_rtt = 50"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where redirect_duration = 0 and redirects_count = 0"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where ssl_negotiation_duration = 114"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where request_duration = 1058"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where response_duration = 17"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where dom_content_loaded_duration = 13"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where load_event_duration = 5"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where unload_event_duration IS NULL"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where t_resp = 1300 and t_page = 907 and t_done = 2207"))
+	s.Assert().Exactly(cntExpect, s.dao.CountRecords("where t_other['boomr_fb'] = 1907"))
 }
 
 func (s *e2eTestSuite) Test_EndToEnd_BeaconFieldsEmpty() {
@@ -12,14 +12,18 @@ import (
 	"time"
 
 	"github.com/basicrum/front_basicrum_go/backup"
+	"github.com/basicrum/front_basicrum_go/beacon"
 	"github.com/basicrum/front_basicrum_go/config"
 	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/deadletter"
+	"github.com/basicrum/front_basicrum_go/erasure"
 	"github.com/basicrum/front_basicrum_go/geoip"
 	"github.com/basicrum/front_basicrum_go/geoip/cloudflare"
 	"github.com/basicrum/front_basicrum_go/geoip/maxmind"
 	"github.com/basicrum/front_basicrum_go/server"
 	"github.com/basicrum/front_basicrum_go/service"
-	"github.com/ua-parser/uap-go/uaparser"
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/basicrum/front_basicrum_go/useragent"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -34,36 +38,52 @@ func main() {
 	}
 
 	// We need to get the Regexes from here: https://github.com/ua-parser/uap-core/blob/master/regexes.yaml
-	userAgentParser, err := uaparser.NewFromBytes(userAgentRegularExpressions)
+	userAgentRegexes, err := loadUserAgentRegexes(sConf.UserAgent.RegexesPath)
 	if err != nil {
 		log.Fatal(err)
 	}
+	log.Printf("user agent regexes version[%v] source[%v]", userAgentRegexes.Version, userAgentRegexes.Source)
+	userAgentParser := beacon.NewUserAgentParser(userAgentRegexes.Parser)
 
-	daoServer := dao.Server(sConf.Database.Host, sConf.Database.Port, sConf.Database.DatabaseName)
-	daoAuth := dao.Auth(sConf.Database.Username, sConf.Database.Password)
-
-	conn, err := dao.NewConnection(
-		daoServer,
-		daoAuth,
-	)
+	database, err := dao.FromConfig(sConf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	conn, err := database.Connect()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	daoService := dao.New(
-		conn,
-		dao.Opts(sConf.Database.TablePrefix),
-	)
+	// the tables are migrated separately with the migrate command when the auto migration is disabled
+	if sConf.Database.AutoMigrate {
+		err = database.Migration().Migrate()
+		if err != nil {
+			log.Fatalf("migrate database ERROR: %+v", err)
+		}
+	}
 
-	migrateDaoService := dao.NewMigrationDAO(
-		daoServer,
-		daoAuth,
-		dao.Opts(sConf.Database.TablePrefix),
-	)
+	daoService := database.DAO(conn, database.TenantMigrator())
 
-	err = migrateDaoService.Migrate()
+	columns, err := daoService.Columns()
 	if err != nil {
-		log.Fatalf("migrate database ERROR: %+v", err)
+		log.Fatal(err)
+	}
+	if err := beacon.DefaultRegistry.Validate(columns); err != nil {
+		log.Fatalf("beacon mapping does not match database ERROR: %+v", err)
+	}
+	// the rollup view and the TTL are DDL too, so they are applied with the rollup and the retention commands
+	// when the auto migration is disabled
+	if sConf.Database.AutoMigrate {
+		if err := daoService.EnableRollup(sConf.Rollup.Enabled); err != nil {
+			log.Fatal(err)
+		}
+		retention, err := types.ParseRetention(sConf.Retention.Days, sConf.Retention.Hostnames)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := daoService.ApplyRetention(retention); err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	geopIPService := geoip.NewComposite(
@@ -78,13 +98,78 @@ func main() {
 		log.Fatal(err)
 	}
 
-	rumEventFactory := service.NewRumEventFactory(userAgentParser, geopIPService)
+	customDataAllowlist, err := beacon.ParseCustomDataAllowlist(sConf.CustomData.Allowlist)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var extraParams *beacon.ExtraParamsCollector
+	if sConf.ExtraParams.Enabled {
+		extraParams = beacon.NewExtraParamsCollector(
+			beacon.DefaultRegistry,
+			sConf.ExtraParams.Denylist,
+			sConf.ExtraParams.MaxSize,
+		)
+	}
+
+	rumEventFactory, err := service.NewRumEventFactory(
+		userAgentParser,
+		geopIPService,
+		service.CacheOpts{
+			Size: sConf.Cache.UserAgentSize,
+			TTL:  time.Duration(sConf.Cache.UserAgentTTLSeconds) * time.Second,
+		},
+		service.CacheOpts{
+			Size: sConf.Cache.GeoIPSize,
+			TTL:  time.Duration(sConf.Cache.GeoIPTTLSeconds) * time.Second,
+		},
+		beacon.NewCustomDataFilter(
+			customDataAllowlist,
+			sConf.CustomData.MaxNames,
+			sConf.CustomData.MaxValueLength,
+		),
+		extraParams,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deadLetterStore, err := database.DeadLetter(deadletter.Storage(sConf.DeadLetter.Storage), sConf.DeadLetter.Path, conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	warnDeadLetter(sConf)
+
 	processingService := service.New(
 		rumEventFactory,
 		daoService,
 		backupService,
+		service.WithDeadLetter(
+			deadLetterStore,
+			sConf.DeadLetter.RetryAttempts,
+			time.Duration(sConf.DeadLetter.RetryDelayMillis)*time.Millisecond,
+		),
+		service.WithQueue(
+			sConf.Server.QueueSize,
+			time.Duration(sConf.Server.QueueTimeoutMillis)*time.Millisecond,
+			sConf.DeadLetter.RetryQueueSize,
+		),
 	)
-	serverFactory := server.NewFactory(processingService, backupService)
+	if sConf.UserAgent.RegexesPath != "" {
+		userAgentReloader := useragent.NewReloader(
+			sConf.UserAgent.RegexesPath,
+			time.Duration(sConf.UserAgent.RegexesReloadSeconds)*time.Second,
+			userAgentParser,
+			userAgentRegexes.Version,
+			rumEventFactory.PurgeUserAgentCache,
+		)
+		go userAgentReloader.Run()
+	}
+
+	erasureService, err := newErasure(sConf, daoService, deadLetterStore)
+	if err != nil {
+		log.Fatal(err)
+	}
+	serverFactory := server.NewFactory(processingService, backupService, daoService, erasureService)
 	servers, err := serverFactory.Build(*sConf)
 	if err != nil {
 		log.Fatal(err)
@@ -98,6 +183,32 @@ func main() {
 	log.Print("Servers exited properly")
 }
 
+func newErasure(sConf *config.StartupConfig, daoService *dao.DAO, deadLetterStore deadletter.IStore) (*erasure.Service, error) {
+	backupDirectory := ""
+	if sConf.Backup.Enabled {
+		backupDirectory = backup.ArchiveDirectory(sConf.Backup.Directory)
+	}
+	return erasure.New(daoService, deadLetterStore, backupDirectory)
+}
+
+// warnDeadLetter logs the dead letter settings which lose the rows failed to be inserted
+func warnDeadLetter(sConf *config.StartupConfig) {
+	if deadletter.Storage(sConf.DeadLetter.Storage) == deadletter.StorageNone {
+		log.Print("WARNING dead letters are disabled with BRUM_DEAD_LETTER_STORAGE=none, the rows failed to be inserted are lost")
+		return
+	}
+	if !sConf.Database.AsyncInsertWait {
+		log.Print("WARNING dead letters store only the connection errors, set BRUM_DATABASE_ASYNC_INSERT_WAIT=true to store the rows rejected by ClickHouse")
+	}
+}
+
+func loadUserAgentRegexes(path string) (*useragent.Regexes, error) {
+	if path == "" {
+		return useragent.Load(userAgentRegularExpressions, "embedded")
+	}
+	return useragent.LoadFile(path)
+}
+
 func startServers(servers []*server.Server) {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/basicrum/front_basicrum_go/types"
+)
+
+const (
+	bearerPrefix         = "Bearer "
+	defaultMetricsPeriod = 24 * time.Hour
+	defaultSeenDays      = 30
+	maxSeenDays          = 366
+	maxErasureBodySize   = 1024 * 1024
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type erasureStatusResponse struct {
+	Tables []types.ErasureStatus `json:"tables"`
+}
+
+type seenHostnamesResponse struct {
+	Hostnames []types.SeenHostname `json:"hostnames"`
+}
+
+// authorized allows the requests with Authorization: Bearer <token> header
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiToken)) != 1 {
+			s.responseJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid api token"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) getMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.responseJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	filter, err := newMetricsFilter(r.URL.Query(), time.Now().UTC())
+	if err != nil {
+		s.responseJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	result, err := s.apiReader.Metrics(filter)
+	if err != nil {
+		log.Printf("get metrics err[%v]", err)
+		s.responseJSON(w, http.StatusInternalServerError, errorResponse{Error: "cannot get metrics"})
+		return
+	}
+	s.responseJSON(w, http.StatusOK, result)
+}
+
+// getSeenHostnames lists the hostnames which sent the beacons, unregistered=true lists only the hostnames without owner
+func (s *Server) getSeenHostnames(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.responseJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	query := r.URL.Query()
+	days := defaultSeenDays
+	if value := query.Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSeenDays {
+			s.responseJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid days[%v] expected 1-%v", value, maxSeenDays)})
+			return
+		}
+		days = parsed
+	}
+	unregisteredOnly := query.Get("unregistered") == "true"
+	hostnames, err := s.apiReader.SeenHostnames(days, unregisteredOnly)
+	if err != nil {
+		log.Printf("get seen hostnames err[%v]", err)
+		s.responseJSON(w, http.StatusInternalServerError, errorResponse{Error: "cannot get seen hostnames"})
+		return
+	}
+	if hostnames == nil {
+		hostnames = []types.SeenHostname{}
+	}
+	s.responseJSON(w, http.StatusOK, seenHostnamesResponse{Hostnames: hostnames})
+}
+
+// erasureHandler starts the erasure job with POST {"session_ids": [...], "page_ids": [...]}
+// GET ?id=... gets the job and GET ?session_id=...&page_id=... gets the progress of the database tables
+func (s *Server) erasureHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.postErasure(w, r)
+	case http.MethodGet:
+		if r.URL.Query().Has("id") {
+			s.getErasureJob(w, r)
+			return
+		}
+		s.getErasureStatus(w, r)
+	default:
+		s.responseJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+	}
+}
+
+// postErasure runs the erasure in background, because the database mutations and the backup rewrite take longer than the write timeout
+func (s *Server) postErasure(w http.ResponseWriter, r *http.Request) {
+	var request types.ErasureRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxErasureBodySize)).Decode(&request); err != nil {
+		s.responseJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request body err[%v]", err)})
+		return
+	}
+	if err := request.Validate(); err != nil {
+		s.responseJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	job, err := s.erasure.Start(request)
+	if err != nil {
+		log.Printf("start erasure sessions[%v] pages[%v] err[%v]", request.SessionIDs, request.PageIDs, err)
+		s.responseJSON(w, http.StatusInternalServerError, errorResponse{Error: "cannot start erasure"})
+		return
+	}
+	s.responseJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) getErasureJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.erasure.Job(r.URL.Query().Get("id"))
+	if !ok {
+		s.responseJSON(w, http.StatusNotFound, errorResponse{Error: "erasure job not found"})
+		return
+	}
+	s.responseJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) getErasureStatus(w http.ResponseWriter, r *http.Request) {
+	request := types.ErasureRequest{
+		SessionIDs: r.URL.Query()["session_id"],
+		PageIDs:    r.URL.Query()["page_id"],
+	}
+	if err := request.Validate(); err != nil {
+		s.responseJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	tables, err := s.erasure.Status(request)
+	if err != nil {
+		log.Printf("get erasure status err[%v]", err)
+		s.responseJSON(w, http.StatusInternalServerError, errorResponse{Error: "cannot get erasure status"})
+		return
+	}
+	s.responseJSON(w, http.StatusOK, erasureStatusResponse{Tables: tables})
+}
+
+// newMetricsFilter creates the filter from the query parameters, the default period is the last 24 hours
+func newMetricsFilter(query url.Values, now time.Time) (types.MetricsFilter, error) {
+	result := types.MetricsFilter{
+		Hostname:    query.Get("hostname"),
+		To:          now,
+		URLPattern:  query.Get("url"),
+		DeviceType:  query.Get("device_type"),
+		CountryCode: query.Get("country"),
+		BrowserName: query.Get("browser"),
+	}
+	var err error
+	if value := query.Get("to"); value != "" {
+		if result.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return result, fmt.Errorf("invalid to[%v] expected RFC3339", value)
+		}
+	}
+	result.From = result.To.Add(-defaultMetricsPeriod)
+	if value := query.Get("from"); value != "" {
+		if result.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return result, fmt.Errorf("invalid from[%v] expected RFC3339", value)
+		}
+	}
+	return result, result.Validate()
+}
+
+func (*Server) responseJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("write json response err[%v]", err)
+	}
+}
@@ -0,0 +1,414 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	backupmocks "github.com/basicrum/front_basicrum_go/backup/mocks"
+	servermocks "github.com/basicrum/front_basicrum_go/server/mocks"
+	servicemocks "github.com/basicrum/front_basicrum_go/service/mocks"
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_getMetrics(t *testing.T) {
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)
+	lcp := 2100.0
+	metrics := &types.Metrics{
+		Hostname:  "www.example.com",
+		From:      from,
+		To:        to,
+		PageViews: 10,
+		Vitals: []types.VitalMetrics{
+			{Name: "lcp", Samples: 4, P50: &lcp, P75: &lcp, P95: &lcp, Good: 0.5, NeedsImprovement: 0.25, Poor: 0.25},
+		},
+	}
+	type expects struct {
+		Metrics         bool
+		MetricsRequest  types.MetricsFilter
+		MetricsResponse *types.Metrics
+		MetricsErr      error
+	}
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		expects       expects
+		want          string
+		wantCode      int
+	}{
+		{
+			name:     "missing token",
+			path:     "/api/v1/metrics?hostname=www.example.com",
+			want:     `{"error":"invalid api token"}` + "\n",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "invalid token",
+			path:          "/api/v1/metrics?hostname=www.example.com",
+			authorization: "Bearer token2",
+			want:          `{"error":"invalid api token"}` + "\n",
+			wantCode:      http.StatusUnauthorized,
+		},
+		{
+			name:          "missing hostname",
+			path:          "/api/v1/metrics",
+			authorization: "Bearer token1",
+			want:          `{"error":"hostname is required"}` + "\n",
+			wantCode:      http.StatusBadRequest,
+		},
+		{
+			name:          "invalid period",
+			path:          "/api/v1/metrics?hostname=www.example.com&from=2023-05-02T00:00:00Z&to=2023-05-01T00:00:00Z",
+			authorization: "Bearer token1",
+			want:          `{"error":"from[2023-05-02T00:00:00Z] must be before to[2023-05-01T00:00:00Z]"}` + "\n",
+			wantCode:      http.StatusBadRequest,
+		},
+		{
+			name:          "success",
+			path:          "/api/v1/metrics?hostname=www.example.com&from=2023-05-01T00:00:00Z&to=2023-05-02T00:00:00Z&url=*/product/*&device_type=mobile&country=DE&browser=Chrome",
+			authorization: "Bearer token1",
+			expects: expects{
+				Metrics: true,
+				MetricsRequest: types.MetricsFilter{
+					Hostname:    "www.example.com",
+					From:        from,
+					To:          to,
+					URLPattern:  "*/product/*",
+					DeviceType:  "mobile",
+					CountryCode: "DE",
+					BrowserName: "Chrome",
+				},
+				MetricsResponse: metrics,
+			},
+			want:     `{"hostname":"www.example.com","from":"2023-05-01T00:00:00Z","to":"2023-05-02T00:00:00Z","page_views":10,"vitals":[{"name":"lcp","samples":4,"p50":2100,"p75":2100,"p95":2100,"good":0.5,"needs_improvement":0.25,"poor":0.25}]}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:          "database error",
+			path:          "/api/v1/metrics?hostname=www.example.com&from=2023-05-01T00:00:00Z&to=2023-05-02T00:00:00Z",
+			authorization: "Bearer token1",
+			expects: expects{
+				Metrics: true,
+				MetricsRequest: types.MetricsFilter{
+					Hostname: "www.example.com",
+					From:     from,
+					To:       to,
+				},
+				MetricsErr: errors.New("connection refused"),
+			},
+			want:     `{"error":"cannot get metrics"}` + "\n",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiReader := servermocks.NewMockIAPIReader(ctrl)
+			port := randomPort()
+			s := New(
+				servicemocks.NewMockIService(ctrl),
+				backupmocks.NewMockIBackup(ctrl),
+				WithHTTP(port),
+				WithAPI(apiReader, "token1"),
+			)
+			go func() {
+				_ = s.Serve()
+			}()
+			defer func() {
+				_ = s.Shutdown(context.Background())
+			}()
+			if tt.expects.Metrics {
+				apiReader.EXPECT().Metrics(tt.expects.MetricsRequest).Return(tt.expects.MetricsResponse, tt.expects.MetricsErr)
+			}
+			waitForServer(t, port)
+			r, err := http.NewRequest(http.MethodGet, makeURL(port, tt.path), nil)
+			require.NoError(t, err)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			response := executeRequest(r, t)
+
+			assertResponse(t, response, tt.want, tt.wantCode)
+		})
+	}
+}
+
+func TestServer_getSeenHostnames(t *testing.T) {
+	lastSeen := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	type expects struct {
+		SeenHostnames                 bool
+		SeenHostnamesDays             int
+		SeenHostnamesUnregisteredOnly bool
+		SeenHostnamesResponse         []types.SeenHostname
+		SeenHostnamesErr              error
+	}
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		expects       expects
+		want          string
+		wantCode      int
+	}{
+		{
+			name:     "missing token",
+			path:     "/api/v1/hostnames/seen",
+			want:     `{"error":"invalid api token"}` + "\n",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "invalid days",
+			path:          "/api/v1/hostnames/seen?days=0",
+			authorization: "Bearer token1",
+			want:          `{"error":"invalid days[0] expected 1-366"}` + "\n",
+			wantCode:      http.StatusBadRequest,
+		},
+		{
+			name:          "default days",
+			path:          "/api/v1/hostnames/seen",
+			authorization: "Bearer token1",
+			expects: expects{
+				SeenHostnames:     true,
+				SeenHostnamesDays: 30,
+			},
+			want:     `{"hostnames":[]}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:          "unregistered",
+			path:          "/api/v1/hostnames/seen?days=7&unregistered=true",
+			authorization: "Bearer token1",
+			expects: expects{
+				SeenHostnames:                 true,
+				SeenHostnamesDays:             7,
+				SeenHostnamesUnregisteredOnly: true,
+				SeenHostnamesResponse: []types.SeenHostname{
+					{Hostname: "www.example.com", LastSeen: lastSeen, Events: 100},
+				},
+			},
+			want:     `{"hostnames":[{"hostname":"www.example.com","last_seen":"2023-05-01T10:00:00Z","events":100,"registered":false}]}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:          "database error",
+			path:          "/api/v1/hostnames/seen",
+			authorization: "Bearer token1",
+			expects: expects{
+				SeenHostnames:     true,
+				SeenHostnamesDays: 30,
+				SeenHostnamesErr:  errors.New("connection refused"),
+			},
+			want:     `{"error":"cannot get seen hostnames"}` + "\n",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiReader := servermocks.NewMockIAPIReader(ctrl)
+			port := randomPort()
+			s := New(
+				servicemocks.NewMockIService(ctrl),
+				backupmocks.NewMockIBackup(ctrl),
+				WithHTTP(port),
+				WithAPI(apiReader, "token1"),
+			)
+			go func() {
+				_ = s.Serve()
+			}()
+			defer func() {
+				_ = s.Shutdown(context.Background())
+			}()
+			if tt.expects.SeenHostnames {
+				apiReader.EXPECT().
+					SeenHostnames(tt.expects.SeenHostnamesDays, tt.expects.SeenHostnamesUnregisteredOnly).
+					Return(tt.expects.SeenHostnamesResponse, tt.expects.SeenHostnamesErr)
+			}
+			waitForServer(t, port)
+			r, err := http.NewRequest(http.MethodGet, makeURL(port, tt.path), nil)
+			require.NoError(t, err)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			response := executeRequest(r, t)
+
+			assertResponse(t, response, tt.want, tt.wantCode)
+		})
+	}
+}
+
+func TestServer_erasureHandler(t *testing.T) {
+	startedAt := time.Date(2023, 9, 21, 3, 0, 0, 0, time.UTC)
+	type expects struct {
+		Start         bool
+		StartRequest  types.ErasureRequest
+		StartErr      error
+		Job           bool
+		JobFound      bool
+		Status        bool
+		StatusRequest types.ErasureRequest
+		StatusErr     error
+	}
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expects  expects
+		want     string
+		wantCode int
+	}{
+		{
+			name:     "invalid body",
+			method:   http.MethodPost,
+			path:     "/api/v1/erasure",
+			body:     `{"session_ids":`,
+			want:     `{"error":"invalid request body err[unexpected EOF]"}` + "\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing ids",
+			method:   http.MethodPost,
+			path:     "/api/v1/erasure",
+			body:     `{"session_ids":[]}`,
+			want:     `{"error":"session ids or page ids are required"}` + "\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "erase",
+			method: http.MethodPost,
+			path:   "/api/v1/erasure",
+			body:   `{"session_ids":["session1","session2"],"page_ids":["page1"]}`,
+			expects: expects{
+				Start:        true,
+				StartRequest: types.ErasureRequest{SessionIDs: []string{"session1", "session2"}, PageIDs: []string{"page1"}},
+			},
+			want: `{"id":"job1","state":"running","result":{"session_ids":["session1","session2"],"page_ids":["page1"],` +
+				`"backup_lines":0,"dead_letter_rows":0},"started_at":"2023-09-21T03:00:00Z"}` + "\n",
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:   "erase error",
+			method: http.MethodPost,
+			path:   "/api/v1/erasure",
+			body:   `{"session_ids":["session1","session2"]}`,
+			expects: expects{
+				Start:        true,
+				StartRequest: types.ErasureRequest{SessionIDs: []string{"session1", "session2"}},
+				StartErr:     errors.New("cache size"),
+			},
+			want:     `{"error":"cannot start erasure"}` + "\n",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "job",
+			method: http.MethodGet,
+			path:   "/api/v1/erasure?id=job1",
+			expects: expects{
+				Job:      true,
+				JobFound: true,
+			},
+			want: `{"id":"job1","state":"running","result":{"session_ids":["session1"],` +
+				`"backup_lines":0,"dead_letter_rows":0},"started_at":"2023-09-21T03:00:00Z"}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "job not found",
+			method: http.MethodGet,
+			path:   "/api/v1/erasure?id=job1",
+			expects: expects{
+				Job: true,
+			},
+			want:     `{"error":"erasure job not found"}` + "\n",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:   "status",
+			method: http.MethodGet,
+			path:   "/api/v1/erasure?session_id=session1&session_id=session2&page_id=page1",
+			expects: expects{
+				Status:        true,
+				StatusRequest: types.ErasureRequest{SessionIDs: []string{"session1", "session2"}, PageIDs: []string{"page1"}},
+			},
+			want:     `{"tables":[{"table":"webperf_rum_events","remaining_rows":2,"pending_mutations":1}]}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "status error",
+			method: http.MethodGet,
+			path:   "/api/v1/erasure?session_id=session1",
+			expects: expects{
+				Status:        true,
+				StatusRequest: types.ErasureRequest{SessionIDs: []string{"session1"}},
+				StatusErr:     errors.New("connection refused"),
+			},
+			want:     `{"error":"cannot get erasure status"}` + "\n",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			erasure := servermocks.NewMockIErasure(ctrl)
+			port := randomPort()
+			s := New(
+				servicemocks.NewMockIService(ctrl),
+				backupmocks.NewMockIBackup(ctrl),
+				WithHTTP(port),
+				WithAPI(servermocks.NewMockIAPIReader(ctrl), "token1"),
+				WithErasure(erasure),
+			)
+			go func() {
+				_ = s.Serve()
+			}()
+			defer func() {
+				_ = s.Shutdown(context.Background())
+			}()
+			if tt.expects.Start {
+				job := types.ErasureJob{
+					ID:        "job1",
+					State:     types.ErasureRunning,
+					Result:    types.ErasureResult{ErasureRequest: tt.expects.StartRequest},
+					StartedAt: startedAt,
+				}
+				erasure.EXPECT().Start(tt.expects.StartRequest).Return(job, tt.expects.StartErr)
+			}
+			if tt.expects.Job {
+				job := types.ErasureJob{
+					ID:        "job1",
+					State:     types.ErasureRunning,
+					Result:    types.ErasureResult{ErasureRequest: types.ErasureRequest{SessionIDs: []string{"session1"}}},
+					StartedAt: startedAt,
+				}
+				erasure.EXPECT().Job("job1").Return(job, tt.expects.JobFound)
+			}
+			if tt.expects.Status {
+				var tables []types.ErasureStatus
+				if tt.expects.StatusErr == nil {
+					tables = []types.ErasureStatus{{Table: "webperf_rum_events", RemainingRows: 2, PendingMutations: 1}}
+				}
+				erasure.EXPECT().Status(tt.expects.StatusRequest).Return(tables, tt.expects.StatusErr)
+			}
+			waitForServer(t, port)
+			r, err := http.NewRequest(tt.method, makeURL(port, tt.path), strings.NewReader(tt.body))
+			require.NoError(t, err)
+			r.Header.Set("Authorization", "Bearer token1")
+			response := executeRequest(r, t)
+
+			assertResponse(t, response, tt.want, tt.wantCode)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IPResolver resolves the client ip address of http request
+// The proxy headers are used only when the request is received from trusted proxy
+type IPResolver struct {
+	trustedProxies []*net.IPNet
+	// clientIPHeader is the single value header set by the trusted proxy, for example CF-Connecting-IP
+	clientIPHeader string
+}
+
+// NewIPResolver creates client ip resolver with trusted proxies list of CIDRs or ip addresses
+// The clientIPHeader is used before the Forwarded and X-Forwarded-For headers, the empty value disables it
+func NewIPResolver(trustedProxies []string, clientIPHeader string) (*IPResolver, error) {
+	result := &IPResolver{
+		clientIPHeader: http.CanonicalHeaderKey(strings.TrimSpace(clientIPHeader)),
+	}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		ipNet, err := parseTrustedProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		result.trustedProxies = append(result.trustedProxies, ipNet)
+	}
+	if result.clientIPHeader != "" && len(result.trustedProxies) == 0 {
+		return nil, fmt.Errorf("client ip header[%v] requires trusted proxies", result.clientIPHeader)
+	}
+	return result, nil
+}
+
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	if strings.Contains(proxy, "/") {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR[%v] err[%w]", proxy, err)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(proxy)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid trusted proxy ip[%v]", proxy)
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// Resolve returns the client ip address of http request
+func (r *IPResolver) Resolve(req *http.Request) string {
+	remoteIP := stripPort(req.RemoteAddr)
+	if !r.isTrusted(remoteIP) {
+		return remoteIP
+	}
+
+	// the single value header cannot be walked, so only the header configured for the trusted proxy is used
+	if r.clientIPHeader != "" {
+		if ip := parseIP(req.Header.Get(r.clientIPHeader)); ip != "" {
+			return ip
+		}
+	}
+
+	if ip := r.fromChain(forwardedFor(req.Header.Values("Forwarded"))); ip != "" {
+		return ip
+	}
+
+	if ip := r.fromChain(splitHeaderValues(req.Header.Values("X-Forwarded-For"))); ip != "" {
+		return ip
+	}
+
+	return remoteIP
+}
+
+// fromChain walks the proxy chain right-to-left and returns the first not trusted hop
+// If all the hops are trusted then the left most hop is returned
+func (r *IPResolver) fromChain(hops []string) string {
+	var leftMost string
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseIP(hops[i])
+		if ip == "" {
+			// unknown or obfuscated identifier - the chain cannot be trusted beyond this hop
+			return leftMost
+		}
+		if !r.isTrusted(ip) {
+			return ip
+		}
+		leftMost = ip
+	}
+	return leftMost
+}
+
+func (r *IPResolver) isTrusted(ipString string) bool {
+	ip := net.ParseIP(ipString)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range r.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the "for" parameters of RFC 7239 Forwarded header values
+func forwardedFor(values []string) []string {
+	var result []string
+	for _, element := range splitHeaderValues(values) {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if !found || !strings.EqualFold(key, "for") {
+				continue
+			}
+			result = append(result, strings.Trim(value, "\""))
+		}
+	}
+	return result
+}
+
+func splitHeaderValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// parseIP returns the normalized ip address without port or empty string if value is not ip address
+func parseIP(value string) string {
+	ip := net.ParseIP(stripPort(strings.TrimSpace(value)))
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// stripPort removes the port from ipv4 "1.2.3.4:80", ipv6 "[::1]:80" and bracketed ipv6 "[::1]" addresses
+func stripPort(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPResolver_Resolve(t *testing.T) {
+	type args struct {
+		remoteAddr string
+		headers    map[string][]string
+	}
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		clientIPHeader string
+		args           args
+		want           string
+	}{
+		{
+			name: "ipv4 remote address without proxy",
+			args: args{
+				remoteAddr: "203.0.113.10:53421",
+			},
+			want: "203.0.113.10",
+		},
+		{
+			name: "ipv6 remote address without proxy",
+			args: args{
+				remoteAddr: "[2001:db8::1]:53421",
+			},
+			want: "2001:db8::1",
+		},
+		{
+			name: "remote address without port",
+			args: args{
+				remoteAddr: "203.0.113.10",
+			},
+			want: "203.0.113.10",
+		},
+		{
+			name: "not trusted remote address ignores headers",
+			args: args{
+				remoteAddr: "203.0.113.10:53421",
+				headers: map[string][]string{
+					"X-Forwarded-For":  {"198.51.100.1"},
+					"X-Real-Ip":        {"198.51.100.2"},
+					"Cf-Connecting-Ip": {"198.51.100.3"},
+				},
+			},
+			want: "203.0.113.10",
+		},
+		{
+			name:           "trusted proxy without headers returns remote address",
+			trustedProxies: []string{"10.0.0.0/8"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+			},
+			want: "10.0.0.1",
+		},
+		{
+			name:           "configured CF-Connecting-IP from trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			clientIPHeader: "CF-Connecting-IP",
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"Cf-Connecting-Ip": {"198.51.100.3"},
+					"X-Forwarded-For":  {"198.51.100.1"},
+				},
+			},
+			want: "198.51.100.3",
+		},
+		{
+			name:           "configured header is case insensitive",
+			trustedProxies: []string{"10.0.0.0/8"},
+			clientIPHeader: "x-real-ip",
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"X-Real-Ip": {"198.51.100.2"},
+				},
+			},
+			want: "198.51.100.2",
+		},
+		{
+			name:           "not configured single value headers are ignored",
+			trustedProxies: []string{"10.0.0.0/8"},
+			clientIPHeader: "CF-Connecting-IP",
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"True-Client-Ip":  {"198.51.100.4"},
+					"X-Real-Ip":       {"198.51.100.2"},
+					"X-Forwarded-For": {"198.51.100.1"},
+				},
+			},
+			want: "198.51.100.1",
+		},
+		{
+			name:           "single value headers are ignored without configuration",
+			trustedProxies: []string{"10.0.0.0/8"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"Cf-Connecting-Ip": {"198.51.100.3"},
+					"X-Real-Ip":        {"198.51.100.2"},
+				},
+			},
+			want: "10.0.0.1",
+		},
+		{
+			name:           "X-Forwarded-For is walked right-to-left",
+			trustedProxies: []string{"10.0.0.0/8", "192.0.2.5"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"X-Forwarded-For": {"1.1.1.1, 198.51.100.1", "192.0.2.5"},
+				},
+			},
+			want: "198.51.100.1",
+		},
+		{
+			name:           "X-Forwarded-For with only trusted hops returns left most",
+			trustedProxies: []string{"10.0.0.0/8"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"},
+				},
+			},
+			want: "10.0.0.3",
+		},
+		{
+			name:           "X-Forwarded-For invalid hop stops the walk",
+			trustedProxies: []string{"10.0.0.0/8"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"X-Forwarded-For": {"198.51.100.1, garbage, 10.0.0.2"},
+				},
+			},
+			want: "10.0.0.2",
+		},
+		{
+			name:           "Forwarded header with ipv6 and port",
+			trustedProxies: []string{"10.0.0.0/8"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"Forwarded":       {`for="[2001:db8::17]:4711";proto=https, for=10.0.0.2`},
+					"X-Forwarded-For": {"198.51.100.1"},
+				},
+			},
+			want: "2001:db8::17",
+		},
+		{
+			name:           "Forwarded header with obfuscated identifier falls back to X-Forwarded-For",
+			trustedProxies: []string{"10.0.0.0/8"},
+			args: args{
+				remoteAddr: "10.0.0.1:53421",
+				headers: map[string][]string{
+					"Forwarded":       {"for=_hidden"},
+					"X-Forwarded-For": {"198.51.100.1"},
+				},
+			},
+			want: "198.51.100.1",
+		},
+		{
+			name:           "trusted ipv6 proxy",
+			trustedProxies: []string{"2001:db8::/32"},
+			args: args{
+				remoteAddr: "[2001:db8::1]:53421",
+				headers: map[string][]string{
+					"X-Forwarded-For": {"198.51.100.1:1234"},
+				},
+			},
+			want: "198.51.100.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewIPResolver(tt.trustedProxies, tt.clientIPHeader)
+			require.NoError(t, err)
+			req := &http.Request{
+				RemoteAddr: tt.args.remoteAddr,
+				Header:     http.Header{},
+			}
+			for k, values := range tt.args.headers {
+				for _, v := range values {
+					req.Header.Add(k, v)
+				}
+			}
+			require.Equal(t, tt.want, r.Resolve(req))
+		})
+	}
+}
+
+func TestNewIPResolver(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		clientIPHeader string
+		wantErr        bool
+	}{
+		{
+			name:           "CIDRs and ip addresses",
+			trustedProxies: []string{"10.0.0.0/8", " 127.0.0.1 ", "::1", ""},
+		},
+		{
+			name:           "invalid CIDR",
+			trustedProxies: []string{"10.0.0.0/33"},
+			wantErr:        true,
+		},
+		{
+			name:           "invalid ip",
+			trustedProxies: []string{"localhost"},
+			wantErr:        true,
+		},
+		{
+			name:           "client ip header without trusted proxies",
+			clientIPHeader: "X-Real-IP",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewIPResolver(tt.trustedProxies, tt.clientIPHeader)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewIPResolver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
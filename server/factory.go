@@ -24,16 +24,22 @@ const (
 type Factory struct {
 	processService *service.Service
 	backupService  backup.IBackup
+	apiReader      IAPIReader
+	erasure        IErasure
 }
 
 // NewFactory returns server factory
 func NewFactory(
 	processService *service.Service,
 	backupService backup.IBackup,
+	apiReader IAPIReader,
+	erasure IErasure,
 ) *Factory {
 	return &Factory{
 		processService: processService,
 		backupService:  backupService,
+		apiReader:      apiReader,
+		erasure:        erasure,
 	}
 }
 
@@ -42,12 +48,20 @@ func (f *Factory) Build(sConf config.StartupConfig) ([]*Server, error) {
 	httpPort := defaultValue(sConf.Server.Port, defaultHTTPPort)
 	httpsPort := defaultValue(sConf.Server.Port, defaultHTTPSPort)
 
+	ipResolver, err := NewIPResolver(sConf.Server.TrustedProxies, sConf.Server.ClientIPHeader)
+	if err != nil {
+		return nil, err
+	}
+
 	if !sConf.Server.SSL {
 		log.Println("HTTP configuration enabled")
 		httpServer := New(
 			f.processService,
 			f.backupService,
 			WithHTTP(httpPort),
+			WithIPResolver(ipResolver),
+			WithAPI(f.apiReader, sConf.PrivateAPI.Token),
+			WithErasure(f.erasure),
 		)
 		return []*Server{httpServer}, nil
 	}
@@ -62,11 +76,16 @@ func (f *Factory) Build(sConf config.StartupConfig) ([]*Server, error) {
 			f.processService,
 			f.backupService,
 			WithTLSConfig(defaultHTTPSPort, tlsConfig),
+			WithIPResolver(ipResolver),
+			WithAPI(f.apiReader, sConf.PrivateAPI.Token),
+			WithErasure(f.erasure),
 		)
+		// the token authenticated API is served only by the HTTPS server
 		httpServer := New(
 			f.processService,
 			f.backupService,
 			WithHTTP(httpPort),
+			WithIPResolver(ipResolver),
 		)
 		return []*Server{httpsServer, httpServer}, nil
 	case config.SSLTypeFile:
@@ -75,11 +94,16 @@ func (f *Factory) Build(sConf config.StartupConfig) ([]*Server, error) {
 			f.processService,
 			f.backupService,
 			WithSSL(httpsPort, sConf.Server.SSLFile.SSLFileCertFile, sConf.Server.SSLFile.SSLFileKeyFile),
+			WithIPResolver(ipResolver),
+			WithAPI(f.apiReader, sConf.PrivateAPI.Token),
+			WithErasure(f.erasure),
 		)
+		// the token authenticated API is served only by the HTTPS server
 		httpServer := New(
 			f.processService,
 			f.backupService,
 			WithHTTP(httpPort),
+			WithIPResolver(ipResolver),
 		)
 		return []*Server{httpsServer, httpServer}, nil
 	default:
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/basicrum/front_basicrum_go/config"
+	servermocks "github.com/basicrum/front_basicrum_go/server/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactory_Build(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	factory := NewFactory(nil, nil, servermocks.NewMockIAPIReader(ctrl), servermocks.NewMockIErasure(ctrl))
+
+	t.Run("http serves api", func(t *testing.T) {
+		sConf := config.StartupConfig{}
+		sConf.PrivateAPI.Token = "token"
+
+		servers, err := factory.Build(sConf)
+		require.NoError(t, err)
+		require.Len(t, servers, 1)
+		require.NotNil(t, servers[0].apiReader)
+	})
+
+	t.Run("ssl serves api only on https", func(t *testing.T) {
+		sConf := config.StartupConfig{}
+		sConf.Server.SSL = true
+		sConf.Server.SSLType = config.SSLTypeFile
+		sConf.PrivateAPI.Token = "token"
+
+		servers, err := factory.Build(sConf)
+		require.NoError(t, err)
+		require.Len(t, servers, 2)
+		require.NotNil(t, servers[0].apiReader)
+		require.NotNil(t, servers[0].erasure)
+		require.Nil(t, servers[1].apiReader)
+		require.Nil(t, servers[1].erasure)
+		require.Empty(t, servers[1].apiToken)
+	})
+}
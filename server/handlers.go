@@ -7,15 +7,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/basicrum/front_basicrum_go/beacon"
 	"github.com/basicrum/front_basicrum_go/types"
 )
 
 func (s *Server) catcher(w http.ResponseWriter, r *http.Request) {
+	// ask the browser to send User-Agent Client Hints with the next beacons
+	s.headersClientHints(w)
+
 	// return no cache headers
 	s.responseNoContent(w)
 
 	// create an event from http request
-	event, err := newEventFromRequest(r)
+	event, err := s.newEventFromRequest(r)
 	if err != nil {
 		log.Printf("failed to parse request %+v", err)
 		return
@@ -32,12 +36,12 @@ func (s *Server) health(w http.ResponseWriter, _ *http.Request) {
 	s.responseOK(w)
 }
 
-func newEventFromRequest(r *http.Request) (*types.Event, error) {
+func (s *Server) newEventFromRequest(r *http.Request) (*types.Event, error) {
 	form, err := parseEventForm(r)
 	if err != nil {
 		return nil, err
 	}
-	ip := getIP(r)
+	ip := s.ipResolver.Resolve(r)
 	return types.NewEvent(form, r.Header, r.UserAgent(), ip), nil
 }
 
@@ -57,20 +61,6 @@ func applyEventFormDefaults(form url.Values) {
 	}
 }
 
-func getIP(r *http.Request) string {
-	var result string
-	var temp string
-
-	result = r.RemoteAddr
-	temp = r.Header.Get("X-Forwarded-For")
-	if result != "" {
-		result = temp
-	}
-
-	parts := strings.Split(result, ",")
-	return parts[0]
-}
-
 func (s *Server) responseNoContent(w http.ResponseWriter) {
 	s.headersNoCache(w, http.StatusNoContent)
 }
@@ -80,6 +70,12 @@ func (s *Server) responseOK(w http.ResponseWriter) {
 	_, _ = w.Write([]byte("ok"))
 }
 
+// headersClientHints requests the hints from the browsers which keep Accept-CH of the beacon server origin
+// The hints of the first beacon depend on Accept-CH of the page, Critical-CH would be ignored on the beacon response
+func (*Server) headersClientHints(w http.ResponseWriter) {
+	w.Header().Set("Accept-CH", strings.Join(beacon.ClientHintsHeaders, ", "))
+}
+
 func (*Server) headersNoCache(w http.ResponseWriter, statusCode int) {
 	// @todo: Check if we need to add more response headers
 	// access-control-allow-credentials: true
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/basicrum/front_basicrum_go/backup"
 	backupmocks "github.com/basicrum/front_basicrum_go/backup/mocks"
@@ -60,6 +62,17 @@ func makeServer(processService *servicemocks.MockIService, backupService backup.
 	return port, s
 }
 
+func waitForServer(t *testing.T, port string) {
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", "localhost:"+port)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
 func randomPort() string {
 	srv := httptest.NewServer(nil)
 	defer srv.Close()
@@ -114,11 +127,12 @@ func TestServer_catcher(t *testing.T) {
 		BackupSaveAsyncRequest *types.Event
 	}
 	tests := []struct {
-		name     string
-		args     args
-		expects  expects
-		want     string
-		wantCode int
+		name         string
+		args         args
+		expects      expects
+		want         string
+		wantCode     int
+		wantAcceptCH string
 	}{
 		{
 			name: "Success",
@@ -131,8 +145,9 @@ func TestServer_catcher(t *testing.T) {
 				BackupSaveAsync:        true,
 				BackupSaveAsyncRequest: expectedEvent,
 			},
-			want:     "",
-			wantCode: http.StatusNoContent,
+			want:         "",
+			wantCode:     http.StatusNoContent,
+			wantAcceptCH: "Sec-CH-UA, Sec-CH-UA-Mobile, Sec-CH-UA-Platform, Sec-CH-UA-Platform-Version, Sec-CH-UA-Full-Version-List, Sec-CH-UA-Model",
 		},
 	}
 	for _, tt := range tests {
@@ -156,10 +171,13 @@ func TestServer_catcher(t *testing.T) {
 			if tt.expects.BackupSaveAsync {
 				backupService.EXPECT().SaveAsync(eqEvent(tt.expects.BackupSaveAsyncRequest))
 			}
+			waitForServer(t, port)
 			address := makeURL(port, "/beacon/catcher")
 			r := makeFormRequest(t, address, tt.args.form)
 			response := executeRequest(r, t)
 
+			require.Equal(t, tt.wantAcceptCH, response.Header.Get("Accept-CH"))
+			require.Empty(t, response.Header.Get("Critical-CH"))
 			assertResponse(t, response, tt.want, tt.wantCode)
 		})
 	}
@@ -1,6 +1,23 @@
 package server
 
+//go:generate mockgen -source=${GOFILE} -destination=mocks/${GOFILE} -package=servermocks
+
+import "github.com/basicrum/front_basicrum_go/types"
+
 // Validator is request validator interface
 type Validator interface {
 	Validate() error
 }
+
+// IAPIReader reads the data of the read API
+type IAPIReader interface {
+	Metrics(filter types.MetricsFilter) (*types.Metrics, error)
+	SeenHostnames(days int, unregisteredOnly bool) ([]types.SeenHostname, error)
+}
+
+// IErasure erases the sessions and the page views
+type IErasure interface {
+	Start(request types.ErasureRequest) (types.ErasureJob, error)
+	Job(id string) (types.ErasureJob, bool)
+	Status(request types.ErasureRequest) ([]types.ErasureStatus, error)
+}
@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: inteface.go
+
+// Package servermocks is a generated GoMock package.
+package servermocks
+
+import (
+	reflect "reflect"
+
+	types "github.com/basicrum/front_basicrum_go/types"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockValidator is a mock of Validator interface.
+type MockValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockValidatorMockRecorder
+}
+
+// MockValidatorMockRecorder is the mock recorder for MockValidator.
+type MockValidatorMockRecorder struct {
+	mock *MockValidator
+}
+
+// NewMockValidator creates a new mock instance.
+func NewMockValidator(ctrl *gomock.Controller) *MockValidator {
+	mock := &MockValidator{ctrl: ctrl}
+	mock.recorder = &MockValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockValidator) EXPECT() *MockValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MockValidator) Validate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockValidatorMockRecorder) Validate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockValidator)(nil).Validate))
+}
+
+// MockIAPIReader is a mock of IAPIReader interface.
+type MockIAPIReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAPIReaderMockRecorder
+}
+
+// MockIAPIReaderMockRecorder is the mock recorder for MockIAPIReader.
+type MockIAPIReaderMockRecorder struct {
+	mock *MockIAPIReader
+}
+
+// NewMockIAPIReader creates a new mock instance.
+func NewMockIAPIReader(ctrl *gomock.Controller) *MockIAPIReader {
+	mock := &MockIAPIReader{ctrl: ctrl}
+	mock.recorder = &MockIAPIReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAPIReader) EXPECT() *MockIAPIReaderMockRecorder {
+	return m.recorder
+}
+
+// Metrics mocks base method.
+func (m *MockIAPIReader) Metrics(filter types.MetricsFilter) (*types.Metrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Metrics", filter)
+	ret0, _ := ret[0].(*types.Metrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metrics indicates an expected call of Metrics.
+func (mr *MockIAPIReaderMockRecorder) Metrics(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metrics", reflect.TypeOf((*MockIAPIReader)(nil).Metrics), filter)
+}
+
+// SeenHostnames mocks base method.
+func (m *MockIAPIReader) SeenHostnames(days int, unregisteredOnly bool) ([]types.SeenHostname, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SeenHostnames", days, unregisteredOnly)
+	ret0, _ := ret[0].([]types.SeenHostname)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SeenHostnames indicates an expected call of SeenHostnames.
+func (mr *MockIAPIReaderMockRecorder) SeenHostnames(days, unregisteredOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SeenHostnames", reflect.TypeOf((*MockIAPIReader)(nil).SeenHostnames), days, unregisteredOnly)
+}
+
+// MockIErasure is a mock of IErasure interface.
+type MockIErasure struct {
+	ctrl     *gomock.Controller
+	recorder *MockIErasureMockRecorder
+}
+
+// MockIErasureMockRecorder is the mock recorder for MockIErasure.
+type MockIErasureMockRecorder struct {
+	mock *MockIErasure
+}
+
+// NewMockIErasure creates a new mock instance.
+func NewMockIErasure(ctrl *gomock.Controller) *MockIErasure {
+	mock := &MockIErasure{ctrl: ctrl}
+	mock.recorder = &MockIErasureMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIErasure) EXPECT() *MockIErasureMockRecorder {
+	return m.recorder
+}
+
+// Job mocks base method.
+func (m *MockIErasure) Job(id string) (types.ErasureJob, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job", id)
+	ret0, _ := ret[0].(types.ErasureJob)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockIErasureMockRecorder) Job(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockIErasure)(nil).Job), id)
+}
+
+// Start mocks base method.
+func (m *MockIErasure) Start(request types.ErasureRequest) (types.ErasureJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", request)
+	ret0, _ := ret[0].(types.ErasureJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockIErasureMockRecorder) Start(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockIErasure)(nil).Start), request)
+}
+
+// Status mocks base method.
+func (m *MockIErasure) Status(request types.ErasureRequest) ([]types.ErasureStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", request)
+	ret0, _ := ret[0].([]types.ErasureStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockIErasureMockRecorder) Status(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockIErasure)(nil).Status), request)
+}
@@ -5,4 +5,11 @@ import "net/http"
 func (s *Server) setupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/beacon/catcher", s.catcher)
 	mux.HandleFunc("/health", s.health)
+	if s.apiReader != nil && s.apiToken != "" {
+		mux.HandleFunc("/api/v1/metrics", s.authorized(s.getMetrics))
+		mux.HandleFunc("/api/v1/hostnames/seen", s.authorized(s.getSeenHostnames))
+	}
+	if s.erasure != nil && s.apiToken != "" {
+		mux.HandleFunc("/api/v1/erasure", s.authorized(s.erasureHandler))
+	}
 }
@@ -15,13 +15,17 @@ import (
 
 // Server represents http or https server
 type Server struct {
-	port      string
-	service   service.IService
-	backup    backup.IBackup
-	certFile  string
-	keyFile   string
-	server    *http.Server
-	tlsConfig *tls.Config
+	port       string
+	service    service.IService
+	backup     backup.IBackup
+	certFile   string
+	keyFile    string
+	server     *http.Server
+	tlsConfig  *tls.Config
+	ipResolver *IPResolver
+	apiReader  IAPIReader
+	apiToken   string
+	erasure    IErasure
 }
 
 // WithHTTP creates server with port
@@ -48,6 +52,29 @@ func WithTLSConfig(port string, tlsConfig *tls.Config) func(*Server) {
 	}
 }
 
+// WithIPResolver creates server with client ip resolver
+func WithIPResolver(ipResolver *IPResolver) func(*Server) {
+	return func(s *Server) {
+		s.ipResolver = ipResolver
+	}
+}
+
+// WithAPI creates server with read API authenticated by the token
+// The API is disabled when the token is empty
+func WithAPI(apiReader IAPIReader, apiToken string) func(*Server) {
+	return func(s *Server) {
+		s.apiReader = apiReader
+		s.apiToken = apiToken
+	}
+}
+
+// WithErasure creates server with erasure API, it requires the API token of WithAPI
+func WithErasure(erasure IErasure) func(*Server) {
+	return func(s *Server) {
+		s.erasure = erasure
+	}
+}
+
 // New creates a new http or https server
 func New(
 	processService service.IService,
@@ -55,8 +82,9 @@ func New(
 	options ...func(*Server),
 ) *Server {
 	result := &Server{
-		service: processService,
-		backup:  backupService,
+		service:    processService,
+		backup:     backupService,
+		ipResolver: &IPResolver{},
 	}
 	for _, o := range options {
 		o(result)
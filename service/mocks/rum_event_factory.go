@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	beacon "github.com/basicrum/front_basicrum_go/beacon"
+	cache "github.com/basicrum/front_basicrum_go/cache"
 	types "github.com/basicrum/front_basicrum_go/types"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -48,3 +49,18 @@ func (mr *MockIRumEventFactoryMockRecorder) Create(event interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIRumEventFactory)(nil).Create), event)
 }
+
+// Stats mocks base method.
+func (m *MockIRumEventFactory) Stats() (cache.Stats, cache.Stats) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(cache.Stats)
+	ret1, _ := ret[1].(cache.Stats)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockIRumEventFactoryMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockIRumEventFactory)(nil).Stats))
+}
@@ -3,37 +3,137 @@ package service
 //go:generate mockgen -source=${GOFILE} -destination=mocks/${GOFILE} -package=servicemocks
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/basicrum/front_basicrum_go/beacon"
+	"github.com/basicrum/front_basicrum_go/cache"
 	"github.com/basicrum/front_basicrum_go/geoip"
+	"github.com/basicrum/front_basicrum_go/geoip/cloudflare"
 	"github.com/basicrum/front_basicrum_go/types"
-	"github.com/ua-parser/uap-go/uaparser"
 )
 
 // IRumEventFactory rum event factory interface
 type IRumEventFactory interface {
 	// Create rum event from http captured event
 	Create(event *types.Event) beacon.RumEvent
+	// Stats returns the user agent and geo ip cache statistics
+	Stats() (userAgent cache.Stats, geoIP cache.Stats)
+}
+
+// CacheOpts contains the cache maximum size and time to live
+// The cache is disabled when size is zero
+type CacheOpts struct {
+	Size int
+	TTL  time.Duration
 }
 
 // RumEventFactory creates rum event
 type RumEventFactory struct {
-	userAgentParser *uaparser.Parser
-	geoIPService    geoip.Service
+	userAgentParser  *cachedUserAgentParser
+	geoIPService     *cachedGeoIPService
+	customDataFilter *beacon.CustomDataFilter
+	extraParams      *beacon.ExtraParamsCollector
 }
 
 // NewRumEventFactory creates rum event factory
 func NewRumEventFactory(
-	userAgentParser *uaparser.Parser,
+	userAgentParser beacon.UserAgentParser,
 	geoIPService geoip.Service,
-) *RumEventFactory {
-	return &RumEventFactory{
-		userAgentParser: userAgentParser,
-		geoIPService:    geoIPService,
+	userAgentCacheOpts CacheOpts,
+	geoIPCacheOpts CacheOpts,
+	customDataFilter *beacon.CustomDataFilter,
+	extraParams *beacon.ExtraParamsCollector,
+) (*RumEventFactory, error) {
+	userAgentCache, err := cache.NewLRU[string, *beacon.UserAgent](userAgentCacheOpts.Size, userAgentCacheOpts.TTL)
+	if err != nil {
+		return nil, err
 	}
+	geoIPCache, err := cache.NewLRU[string, geoLocation](geoIPCacheOpts.Size, geoIPCacheOpts.TTL)
+	if err != nil {
+		return nil, err
+	}
+	return &RumEventFactory{
+		userAgentParser: &cachedUserAgentParser{
+			parser: userAgentParser,
+			cache:  userAgentCache,
+		},
+		geoIPService: &cachedGeoIPService{
+			service: geoIPService,
+			cache:   geoIPCache,
+		},
+		customDataFilter: customDataFilter,
+		extraParams:      extraParams,
+	}, nil
 }
 
 // Create rum event from http captured event
 func (s *RumEventFactory) Create(event *types.Event) beacon.RumEvent {
 	beaconEvent := beacon.FromEvent(event)
-	return beacon.ConvertToRumEvent(beaconEvent, event, s.userAgentParser, s.geoIPService)
+	rumEvent := beacon.ConvertToRumEvent(beaconEvent, event, s.userAgentParser, s.geoIPService)
+	s.customDataFilter.Filter(&rumEvent)
+	rumEvent.Extra_Params = s.extraParams.Collect(event.RequestParameters)
+	return rumEvent
+}
+
+// Stats returns the user agent and geo ip cache statistics
+func (s *RumEventFactory) Stats() (userAgent cache.Stats, geoIP cache.Stats) {
+	return s.userAgentParser.cache.Stats(), s.geoIPService.cache.Stats()
+}
+
+// PurgeUserAgentCache removes the cached user agents parsed with previous regular expressions
+func (s *RumEventFactory) PurgeUserAgentCache() {
+	s.userAgentParser.cache.Purge()
+}
+
+type cachedUserAgentParser struct {
+	parser beacon.UserAgentParser
+	cache  *cache.LRU[string, *beacon.UserAgent]
+}
+
+// Parse returns the cached parsed user agent or parses and caches it
+func (p *cachedUserAgentParser) Parse(userAgent string) *beacon.UserAgent {
+	if result, ok := p.cache.Get(userAgent); ok {
+		return result
+	}
+	result := p.parser.Parse(userAgent)
+	p.cache.Add(userAgent, result)
+	return result
+}
+
+type geoLocation struct {
+	country string
+	city    string
+}
+
+type cachedGeoIPService struct {
+	service geoip.Service
+	cache   *cache.LRU[string, geoLocation]
+}
+
+// CountryAndCity returns the cached country and city by ip address and cloudflare headers or looks them up and caches them
+// nolint: revive
+func (s *cachedGeoIPService) CountryAndCity(header http.Header, ipString string) (string, string, error) {
+	if s.service == nil {
+		return "", "", nil
+	}
+	if ipString == "" {
+		return s.service.CountryAndCity(header, ipString)
+	}
+	key := geoIPCacheKey(header, ipString)
+	if result, ok := s.cache.Get(key); ok {
+		return result.country, result.city, nil
+	}
+	country, city, err := s.service.CountryAndCity(header, ipString)
+	if err != nil {
+		return country, city, err
+	}
+	s.cache.Add(key, geoLocation{country: country, city: city})
+	return country, city, nil
+}
+
+// geoIPCacheKey contains the cloudflare headers because the composite service prefers them over the ip address lookup
+func geoIPCacheKey(header http.Header, ipString string) string {
+	return strings.Join([]string{ipString, header.Get(cloudflare.CountryHeader), header.Get(cloudflare.CityHeader)}, "|")
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/basicrum/front_basicrum_go/beacon"
+	"github.com/basicrum/front_basicrum_go/cache"
+	"github.com/basicrum/front_basicrum_go/types"
+	"github.com/stretchr/testify/require"
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+type countingUserAgentParser struct {
+	calls int
+}
+
+func (p *countingUserAgentParser) Parse(_ string) *beacon.UserAgent {
+	p.calls++
+	return &beacon.UserAgent{
+		Client: &uaparser.Client{
+			UserAgent: &uaparser.UserAgent{Family: "Chrome"},
+			Os:        &uaparser.Os{Family: "Windows"},
+			Device:    &uaparser.Device{},
+		},
+	}
+}
+
+type countingGeoIPService struct {
+	calls int
+	err   error
+}
+
+// nolint: revive
+func (s *countingGeoIPService) CountryAndCity(_ http.Header, _ string) (string, string, error) {
+	s.calls++
+	return "BG", "Sofia", s.err
+}
+
+func TestRumEventFactory_Create_cache(t *testing.T) {
+	tests := []struct {
+		name               string
+		cacheOpts          CacheOpts
+		geoIPErr           error
+		wantParserCalls    int
+		wantGeoIPCalls     int
+		wantUserAgentStats cache.Stats
+		wantGeoIPStats     cache.Stats
+	}{
+		{
+			name:               "cache enabled",
+			cacheOpts:          CacheOpts{Size: 10, TTL: time.Hour},
+			wantParserCalls:    1,
+			wantGeoIPCalls:     1,
+			wantUserAgentStats: cache.Stats{Hits: 2, Misses: 1, Size: 1},
+			wantGeoIPStats:     cache.Stats{Hits: 2, Misses: 1, Size: 1},
+		},
+		{
+			name:               "cache disabled",
+			cacheOpts:          CacheOpts{},
+			wantParserCalls:    3,
+			wantGeoIPCalls:     3,
+			wantUserAgentStats: cache.Stats{Misses: 3},
+			wantGeoIPStats:     cache.Stats{Misses: 3},
+		},
+		{
+			name:               "geo ip errors are not cached",
+			cacheOpts:          CacheOpts{Size: 10, TTL: time.Hour},
+			geoIPErr:           errors.New("lookup failed"),
+			wantParserCalls:    1,
+			wantGeoIPCalls:     3,
+			wantUserAgentStats: cache.Stats{Hits: 2, Misses: 1, Size: 1},
+			wantGeoIPStats:     cache.Stats{Misses: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &countingUserAgentParser{}
+			geoIPService := &countingGeoIPService{err: tt.geoIPErr}
+			s, err := NewRumEventFactory(parser, geoIPService, tt.cacheOpts, tt.cacheOpts, beacon.NewCustomDataFilter(nil, 0, 0), nil)
+			require.NoError(t, err)
+
+			event := types.NewEvent(nil, http.Header{}, "Mozilla/5.0", "1.2.3.4")
+			for i := 0; i < 3; i++ {
+				rumEvent := s.Create(event)
+				require.Equal(t, "desktop", rumEvent.Device_Type)
+				require.Equal(t, "Chrome", rumEvent.Browser_Name)
+			}
+
+			require.Equal(t, tt.wantParserCalls, parser.calls)
+			require.Equal(t, tt.wantGeoIPCalls, geoIPService.calls)
+			userAgentStats, geoIPStats := s.Stats()
+			require.Equal(t, tt.wantUserAgentStats, userAgentStats)
+			require.Equal(t, tt.wantGeoIPStats, geoIPStats)
+		})
+	}
+}
+
+func TestRumEventFactory_Create_geoIPCacheHeaders(t *testing.T) {
+	geoIPService := &countingGeoIPService{}
+	s, err := NewRumEventFactory(
+		&countingUserAgentParser{},
+		geoIPService,
+		CacheOpts{Size: 10, TTL: time.Hour},
+		CacheOpts{Size: 10, TTL: time.Hour},
+		beacon.NewCustomDataFilter(nil, 0, 0),
+		nil,
+	)
+	require.NoError(t, err)
+
+	// the same ip address with the different cloudflare country is looked up again
+	for _, country := range []string{"BG", "DE", "BG"} {
+		header := http.Header{}
+		header.Set("CF-IPCountry", country)
+		s.Create(types.NewEvent(nil, header, "Mozilla/5.0", "1.2.3.4"))
+	}
+
+	require.Equal(t, 2, geoIPService.calls)
+}
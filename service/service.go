@@ -3,16 +3,35 @@ package service
 //go:generate mockgen -source=${GOFILE} -destination=mocks/${GOFILE} -package=servicemocks
 
 import (
+	"errors"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/basicrum/front_basicrum_go/backup"
 	"github.com/basicrum/front_basicrum_go/beacon"
 	"github.com/basicrum/front_basicrum_go/dao"
+	"github.com/basicrum/front_basicrum_go/deadletter"
 	"github.com/basicrum/front_basicrum_go/types"
 )
 
-const hostUpdateDuration = time.Minute
+const (
+	hostUpdateDuration    = time.Minute
+	defaultQueueSize      = 10000
+	defaultRetryQueueSize = 1000
+	defaultQueueTimeout   = 100 * time.Millisecond
+)
+
+// errQueueFull is the dead letter error of the events which did not fit into the queue
+// nolint: gochecknoglobals
+var errQueueFull = errors.New("event queue is full")
+
+// eventSaver saves the rows of the rum event by its type
+type eventSaver interface {
+	Save(rumEvent beacon.RumEvent) error
+	SaveXhr(event beacon.XhrEvent) error
+	SaveErrors(events []beacon.ErrorEvent) error
+}
 
 // IService service interface
 type IService interface {
@@ -31,8 +50,44 @@ type Service struct {
 	rumEventFactory IRumEventFactory
 	daoService      dao.IDAO
 	events          chan *types.Event
+	retries         chan retry
+	overflow        atomic.Uint64
 	hosts           map[string]string
 	backupService   backup.IBackup
+	deadLetter      deadletter.IStore
+	retryAttempts   int
+	retryDelay      time.Duration
+	queueSize       int
+	queueTimeout    time.Duration
+	retryQueueSize  int
+}
+
+// retry is the failed insert waiting for the next attempt
+type retry struct {
+	event *types.Event
+	save  func() error
+	err   error
+}
+
+// WithDeadLetter creates service retrying the failed inserts and storing the rows in dead letter storage
+// The retry delay increases with every attempt
+func WithDeadLetter(store deadletter.IStore, retryAttempts int, retryDelay time.Duration) func(*Service) {
+	return func(s *Service) {
+		s.deadLetter = store
+		s.retryAttempts = retryAttempts
+		s.retryDelay = retryDelay
+	}
+}
+
+// WithQueue limits the events waiting for the processing and the failed inserts waiting for the retry
+// The event waits for the queue timeout when the queue is full and then it is stored in dead letter storage,
+// the failed inserts are stored in dead letter storage when the retry queue is full
+func WithQueue(queueSize int, queueTimeout time.Duration, retryQueueSize int) func(*Service) {
+	return func(s *Service) {
+		s.queueSize = queueSize
+		s.queueTimeout = queueTimeout
+		s.retryQueueSize = retryQueueSize
+	}
 }
 
 // New creates processing service
@@ -41,26 +96,59 @@ func New(
 	rumEventFactory IRumEventFactory,
 	daoService dao.IDAO,
 	backupService backup.IBackup,
+	options ...func(*Service),
 ) *Service {
-	events := make(chan *types.Event)
-	return &Service{
+	result := &Service{
 		rumEventFactory: rumEventFactory,
 		daoService:      daoService,
-		events:          events,
 		hosts:           map[string]string{},
 		backupService:   backupService,
+		deadLetter:      deadletter.NewNullStore(),
+		retryAttempts:   1,
+		queueSize:       defaultQueueSize,
+		queueTimeout:    defaultQueueTimeout,
+		retryQueueSize:  defaultRetryQueueSize,
 	}
+	for _, o := range options {
+		o(result)
+	}
+	result.events = make(chan *types.Event, result.queueSize)
+	result.retries = make(chan retry, result.retryQueueSize)
+	return result
 }
 
 // SaveAsync saves an event asynchronously
+// When the queue stays full for the queue timeout the event rows are stored in dead letter storage
 func (s *Service) SaveAsync(event *types.Event) {
-	go func() {
-		s.events <- event
-	}()
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+	select {
+	case s.events <- event:
+	case <-timer.C:
+		s.overflow.Add(1)
+		s.storeOverflow(event)
+	}
+}
+
+// storeOverflow stores the rows of the event which did not fit into the queue in dead letter storage
+// It runs in the request goroutine, so it does not change the hosts of the processing goroutine
+func (s *Service) storeOverflow(event *types.Event) {
+	rumEvent := s.rumEventFactory.Create(event)
+	s.saveRumEvent(rumEvent, dao.Rejecter{Err: errQueueFull}, func(save func() error) error {
+		s.storeDeadLetter(event, save())
+		return nil
+	})
 }
 
 // Run process the events from the channel and save them in datastore (click house)
+// The failed inserts are retried in separate goroutine, so the slow retries do not block the events
 func (s *Service) Run() {
+	go s.runRetries()
 	updateHostTicker := time.NewTicker(hostUpdateDuration)
 	for {
 		select {
@@ -68,6 +156,7 @@ func (s *Service) Run() {
 			s.processEvent(event)
 		case <-updateHostTicker.C:
 			s.processHosts()
+			s.logStats()
 		}
 	}
 }
@@ -89,15 +178,94 @@ func (s *Service) processEvent(event *types.Event) {
 		return
 	}
 	rumEvent := s.rumEventFactory.Create(event)
-	s.processRumEvent(rumEvent)
+	s.processRumEvent(event, rumEvent)
+}
+
+func (s *Service) processRumEvent(event *types.Event, rumEvent beacon.RumEvent) {
+	s.saveRumEvent(rumEvent, s.daoService, func(save func() error) error {
+		return s.persist(event, save)
+	})
+	s.hosts[rumEvent.Hostname] = rumEvent.Created_At
 }
 
-func (s *Service) processRumEvent(rumEvent beacon.RumEvent) {
-	err := s.daoService.Save(rumEvent)
+// saveRumEvent saves the rows of the event type with the saver, persist calls the save and handles its failure
+func (s *Service) saveRumEvent(rumEvent beacon.RumEvent, saver eventSaver, persist func(save func() error) error) {
+	var err error
+	switch rumEvent.Event_Type {
+	case beacon.EventTypeXhr:
+		err = persist(func() error {
+			return saver.SaveXhr(beacon.NewXhrEvent(rumEvent))
+		})
+	case beacon.EventTypeError:
+		// the error beacons contain only the errors
+	default:
+		err = persist(func() error {
+			return saver.Save(rumEvent)
+		})
+	}
 	if err != nil {
 		log.Printf("failed to save data: %+v err: %+v", rumEvent, err)
 	}
-	s.hosts[rumEvent.Hostname] = rumEvent.Created_At
+	if len(rumEvent.Errors) > 0 {
+		err := persist(func() error {
+			return saver.SaveErrors(beacon.NewErrorEvents(rumEvent))
+		})
+		if err != nil {
+			log.Printf("failed to save errors: %+v err: %+v", rumEvent.Errors, err)
+		}
+	}
+}
+
+// persist calls save and queues the failed insert for the retry
+// The rows of the failed insert are stored in dead letter storage when there are no retry attempts or the retry queue is full
+func (s *Service) persist(event *types.Event, save func() error) error {
+	err := save()
+	if err == nil {
+		return nil
+	}
+	if s.retryAttempts > 1 {
+		select {
+		case s.retries <- retry{event: event, save: save, err: err}:
+			return nil
+		default:
+			log.Printf("retry queue is full")
+		}
+	}
+	s.storeDeadLetter(event, err)
+	return err
+}
+
+func (s *Service) runRetries() {
+	for r := range s.retries {
+		if err := s.retry(r); err != nil {
+			log.Printf("failed to save data after retries err: %+v", err)
+		}
+	}
+}
+
+// retry calls save with the increasing delay until it succeeds or the retry attempts are exhausted
+func (s *Service) retry(r retry) error {
+	err := r.err
+	for attempt := 2; attempt <= s.retryAttempts; attempt++ {
+		time.Sleep(s.retryDelay * time.Duration(attempt-1))
+		if err = r.save(); err == nil {
+			return nil
+		}
+	}
+	s.storeDeadLetter(r.event, err)
+	return err
+}
+
+// storeDeadLetter stores the rows of the failed insert, the other errors are only logged
+func (s *Service) storeDeadLetter(event *types.Event, err error) {
+	var insertErr *dao.InsertError
+	if !errors.As(err, &insertErr) {
+		return
+	}
+	entry := deadletter.NewEntry(insertErr.Table, insertErr.Rows, event.RequestParameters, err, time.Now())
+	if storeErr := s.deadLetter.Store(entry); storeErr != nil {
+		log.Printf("failed to store dead letter table[%v] err: %v", insertErr.Table, storeErr)
+	}
 }
 
 func (s *Service) processHosts() {
@@ -115,6 +283,16 @@ func (s *Service) saveHost(hostname string, createdAt string) {
 	}
 }
 
+func (s *Service) logStats() {
+	userAgent, geoIP := s.rumEventFactory.Stats()
+	log.Printf(
+		"cache stats user agent hits[%v] misses[%v] size[%v] ratio[%.2f] geo ip hits[%v] misses[%v] size[%v] ratio[%.2f]",
+		userAgent.Hits, userAgent.Misses, userAgent.Size, userAgent.HitRatio(),
+		geoIP.Hits, geoIP.Misses, geoIP.Size, geoIP.HitRatio(),
+	)
+	log.Printf("queue events[%v] retries[%v] overflow events[%v]", len(s.events), len(s.retries), s.overflow.Load())
+}
+
 func (s *Service) clearHosts() {
 	s.hosts = map[string]string{}
 }
@@ -1,25 +1,35 @@
 package service
 
 import (
+	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	backupmocks "github.com/basicrum/front_basicrum_go/backup/mocks"
 	"github.com/basicrum/front_basicrum_go/beacon"
+	"github.com/basicrum/front_basicrum_go/dao"
 	daomocks "github.com/basicrum/front_basicrum_go/dao/mocks"
+	"github.com/basicrum/front_basicrum_go/deadletter"
+	deadlettermocks "github.com/basicrum/front_basicrum_go/deadletter/mocks"
 	servicemocks "github.com/basicrum/front_basicrum_go/service/mocks"
 	"github.com/basicrum/front_basicrum_go/types"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestService_processEvent(t *testing.T) {
 	type expects struct {
-		Create    bool
-		Save      bool
-		SaveError error
+		Create     bool
+		Save       bool
+		SaveXhr    bool
+		SaveErrors bool
+		SaveError  error
 	}
 	type args struct {
-		nilEvent bool
+		nilEvent  bool
+		eventType string
+		errors    []beacon.JSError
 	}
 	tests := []struct {
 		name    string
@@ -39,6 +49,38 @@ func TestService_processEvent(t *testing.T) {
 				Save:   true,
 			},
 		},
+		{
+			name: "should save the xhr event into xhr table",
+			expects: expects{
+				Create:  true,
+				SaveXhr: true,
+			},
+			args: args{
+				eventType: beacon.EventTypeXhr,
+			},
+		},
+		{
+			name: "should save the page load errors into errors table",
+			expects: expects{
+				Create:     true,
+				Save:       true,
+				SaveErrors: true,
+			},
+			args: args{
+				errors: []beacon.JSError{{Message: "Oops"}},
+			},
+		},
+		{
+			name: "should save only the errors of error beacon",
+			expects: expects{
+				Create:     true,
+				SaveErrors: true,
+			},
+			args: args{
+				eventType: beacon.EventTypeError,
+				errors:    []beacon.JSError{{Message: "Oops"}},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -70,7 +112,9 @@ func TestService_processEvent(t *testing.T) {
 
 			// expects
 			rumEvent := beacon.RumEvent{
-				Hostname: hostname,
+				Hostname:   hostname,
+				Event_Type: tt.args.eventType,
+				Errors:     tt.args.errors,
 			}
 			if tt.expects.Create {
 				rumEventFactory.EXPECT().Create(testEvent).Return(rumEvent)
@@ -78,9 +122,133 @@ func TestService_processEvent(t *testing.T) {
 			if tt.expects.Save {
 				daoService.EXPECT().Save(rumEvent).Return(tt.expects.SaveError)
 			}
+			if tt.expects.SaveXhr {
+				daoService.EXPECT().SaveXhr(beacon.NewXhrEvent(rumEvent)).Return(tt.expects.SaveError)
+			}
+			if tt.expects.SaveErrors {
+				daoService.EXPECT().SaveErrors(beacon.NewErrorEvents(rumEvent)).Return(tt.expects.SaveError)
+			}
 
 			// when
 			s.processEvent(inputEvent)
 		})
 	}
 }
+
+func TestService_persist(t *testing.T) {
+	insertErr := &dao.InsertError{Table: "webperf_rum_events", Rows: []byte(`{"hostname":"hostname1"}`), Err: errors.New("connection refused")}
+	tests := []struct {
+		name           string
+		errors         []error
+		retryQueueSize int
+		retried        bool
+		deadLetter     bool
+		wantErr        bool
+	}{
+		{
+			name:           "should save at first attempt",
+			errors:         []error{nil},
+			retryQueueSize: 1,
+		},
+		{
+			name:           "should retry the failed insert",
+			errors:         []error{insertErr, nil},
+			retryQueueSize: 1,
+			retried:        true,
+		},
+		{
+			name:           "should store dead letter after the retries",
+			errors:         []error{insertErr, insertErr, insertErr},
+			retryQueueSize: 1,
+			retried:        true,
+			deadLetter:     true,
+			wantErr:        true,
+		},
+		{
+			name:           "should not store dead letter of not insert error",
+			errors:         []error{errors.New("json error"), errors.New("json error"), errors.New("json error")},
+			retryQueueSize: 1,
+			retried:        true,
+			wantErr:        true,
+		},
+		{
+			name:       "should store dead letter when the retry queue is full",
+			errors:     []error{insertErr},
+			deadLetter: true,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			deadLetter := deadlettermocks.NewMockIStore(ctrl)
+			s := New(nil, nil, nil, WithDeadLetter(deadLetter, 3, time.Millisecond), WithQueue(1, time.Millisecond, tt.retryQueueSize))
+			event := &types.Event{
+				RequestParameters: url.Values{
+					"u": []string{"https://hostname1/"},
+				},
+			}
+
+			if tt.deadLetter {
+				deadLetter.EXPECT().Store(gomock.Any()).DoAndReturn(func(entry deadletter.Entry) error {
+					require.Equal(t, "webperf_rum_events", entry.Table_Name)
+					require.Equal(t, `{"hostname":"hostname1"}`, entry.Data)
+					require.Equal(t, map[string]string{"u": "https://hostname1/"}, entry.Parameters)
+					require.Contains(t, entry.Error, "connection refused")
+					return nil
+				})
+			}
+
+			attempts := 0
+			err := s.persist(event, func() error {
+				err := tt.errors[attempts]
+				attempts++
+				return err
+			})
+			require.Equal(t, tt.retried, len(s.retries) == 1)
+			if tt.retried {
+				require.NoError(t, err)
+				err = s.retry(<-s.retries)
+			}
+
+			require.Equal(t, len(tt.errors), attempts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_SaveAsync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rumEventFactory := servicemocks.NewMockIRumEventFactory(ctrl)
+	deadLetter := deadlettermocks.NewMockIStore(ctrl)
+	s := New(rumEventFactory, nil, nil, WithDeadLetter(deadLetter, 3, time.Millisecond), WithQueue(1, time.Millisecond, 1))
+	queued := &types.Event{}
+	overflow := &types.Event{RequestParameters: url.Values{"u": []string{"https://hostname1/"}}}
+	rumEventFactory.EXPECT().Create(overflow).Return(beacon.RumEvent{
+		Hostname:   "hostname1",
+		Event_Type: beacon.EventTypeVisitPage,
+		Errors:     []beacon.JSError{{Message: "a"}},
+	})
+	var tables []string
+	deadLetter.EXPECT().Store(gomock.Any()).DoAndReturn(func(entry deadletter.Entry) error {
+		tables = append(tables, entry.Table_Name)
+		require.Equal(t, map[string]string{"u": "https://hostname1/"}, entry.Parameters)
+		require.Equal(t, "clickhouse insert table["+entry.Table_Name+"] failed: event queue is full", entry.Error)
+		return nil
+	}).Times(2)
+
+	s.SaveAsync(queued)
+	s.SaveAsync(overflow)
+
+	require.Len(t, s.events, 1)
+	require.Equal(t, uint64(1), s.overflow.Load())
+	require.Equal(t, []string{"webperf_rum_events", "webperf_rum_errors"}, tables)
+}
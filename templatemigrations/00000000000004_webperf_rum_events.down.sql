@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events DROP COLUMN device_model, DROP COLUMN user_agent_source
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN device_model LowCardinality(Nullable(String)), ADD COLUMN user_agent_source LowCardinality(String) DEFAULT 'user_agent'
\ No newline at end of file
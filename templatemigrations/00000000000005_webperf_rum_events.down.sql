@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events DROP COLUMN ssl_negotiation_duration, DROP COLUMN app_cache_duration, DROP COLUMN request_duration, DROP COLUMN response_duration, DROP COLUMN dom_interactive, DROP COLUMN dom_processing_duration, DROP COLUMN dom_content_loaded_duration, DROP COLUMN load_event_duration, DROP COLUMN unload_event_duration
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN ssl_negotiation_duration Nullable(UInt16), ADD COLUMN app_cache_duration Nullable(UInt16), ADD COLUMN request_duration Nullable(UInt16), ADD COLUMN response_duration Nullable(UInt16), ADD COLUMN dom_interactive Nullable(UInt16), ADD COLUMN dom_processing_duration Nullable(UInt16), ADD COLUMN dom_content_loaded_duration Nullable(UInt16), ADD COLUMN load_event_duration Nullable(UInt16), ADD COLUMN unload_event_duration Nullable(UInt16)
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events DROP COLUMN t_resp, DROP COLUMN t_page, DROP COLUMN t_done, DROP COLUMN t_other
\ No newline at end of file
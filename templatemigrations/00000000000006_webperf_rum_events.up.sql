@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN t_resp Nullable(UInt32), ADD COLUMN t_page Nullable(UInt32), ADD COLUMN t_done Nullable(UInt32), ADD COLUMN t_other Map(String, UInt32)
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events DROP COLUMN interaction_to_next_paint, DROP COLUMN inp_target, DROP COLUMN inp_time, DROP COLUMN time_to_first_byte, DROP COLUMN lcp_element, DROP COLUMN lcp_element_selector, DROP COLUMN lcp_resource_url, DROP COLUMN lcp_size, DROP COLUMN cls_source, DROP COLUMN cls_time
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN interaction_to_next_paint Nullable(UInt16), ADD COLUMN inp_target Nullable(String), ADD COLUMN inp_time Nullable(UInt32), ADD COLUMN time_to_first_byte Nullable(UInt16) ALIAS first_byte_duration, ADD COLUMN lcp_element LowCardinality(Nullable(String)), ADD COLUMN lcp_element_selector Nullable(String), ADD COLUMN lcp_resource_url Nullable(String), ADD COLUMN lcp_size Nullable(UInt32), ADD COLUMN cls_source Nullable(String), ADD COLUMN cls_time Nullable(UInt32)
\ No newline at end of file
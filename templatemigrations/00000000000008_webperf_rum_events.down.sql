@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events DROP COLUMN custom_metrics, DROP COLUMN custom_dimensions, DROP COLUMN custom_timers
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN custom_metrics Map(String, Float64), ADD COLUMN custom_dimensions Map(LowCardinality(String), String), ADD COLUMN custom_timers Map(String, Float64)
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN dom_script_ext Nullable(UInt16)
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN extra_params Map(String, String)
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN route_change_duration Nullable(UInt32)
\ No newline at end of file
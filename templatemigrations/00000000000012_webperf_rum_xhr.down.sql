@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {prefix}webperf_rum_xhr
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS {prefix}webperf_rum_xhr (
+    event_date                      Date DEFAULT toDate(created_at),
+    hostname                        LowCardinality(String),
+    created_at                      DateTime,
+    page_url                        String,
+    request_url                     String,
+    request_method                  LowCardinality(String),
+    request_type                    LowCardinality(String),
+    status                          Nullable(Int16),
+    duration                        Nullable(UInt32),
+    session_id                      FixedString(43),
+    page_id                         FixedString(8),
+    device_type                     LowCardinality(String),
+    browser_name                    LowCardinality(String),
+    operating_system                LowCardinality(String),
+    geo_country_code                FixedString(2),
+    user_agent                      Nullable(String)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(event_date)
+ORDER BY (hostname, event_date)
+SETTINGS index_granularity = 8192
\ No newline at end of file
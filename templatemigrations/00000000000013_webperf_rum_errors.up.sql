@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS {prefix}webperf_rum_errors (
+    event_date                      Date DEFAULT toDate(created_at),
+    hostname                        LowCardinality(String),
+    created_at                      DateTime,
+    url                             String,
+    session_id                      FixedString(43),
+    page_id                         FixedString(8),
+    message                         String,
+    type                            LowCardinality(String),
+    code                            Nullable(String),
+    stack                           String,
+    source                          LowCardinality(String),
+    via                             LowCardinality(String),
+    count                           UInt32,
+    file_name                       String,
+    line_number                     UInt32,
+    column_number                   UInt32,
+    fingerprint                     UInt64,
+    device_type                     LowCardinality(String),
+    browser_name                    LowCardinality(String),
+    operating_system                LowCardinality(String),
+    geo_country_code                FixedString(2)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(event_date)
+ORDER BY (hostname, event_date, fingerprint)
+SETTINGS index_granularity = 8192
\ No newline at end of file
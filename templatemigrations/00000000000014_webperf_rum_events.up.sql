@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN rejections Map(LowCardinality(String), String)
\ No newline at end of file
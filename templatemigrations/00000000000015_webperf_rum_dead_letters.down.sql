@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {prefix}webperf_rum_dead_letters
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS {prefix}webperf_rum_dead_letters (
+    id                              String,
+    failed_at                       DateTime64(3),
+    table_name                      LowCardinality(String),
+    data                            String,
+    error                           String,
+    parameters                      Map(String, String)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(failed_at)
+ORDER BY failed_at
+SETTINGS index_granularity = 8192
\ No newline at end of file
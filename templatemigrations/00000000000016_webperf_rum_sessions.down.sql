@@ -0,0 +1,9 @@
+DROP VIEW IF EXISTS {prefix}webperf_rum_sessions_summary
+
+--migration:split
+
+DROP VIEW IF EXISTS {prefix}webperf_rum_sessions_mv
+
+--migration:split
+
+DROP TABLE IF EXISTS {prefix}webperf_rum_sessions
\ No newline at end of file
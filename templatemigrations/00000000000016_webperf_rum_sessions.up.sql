@@ -0,0 +1,72 @@
+CREATE TABLE IF NOT EXISTS {prefix}webperf_rum_sessions (
+    event_date                      Date,
+    hostname                        LowCardinality(String),
+    session_id                      FixedString(43),
+    start_time                      SimpleAggregateFunction(min, DateTime),
+    end_time                        SimpleAggregateFunction(max, DateTime),
+    page_count                      SimpleAggregateFunction(sum, UInt64),
+    landing_url                     AggregateFunction(argMin, String, DateTime),
+    exit_url                        AggregateFunction(argMax, String, DateTime),
+    device_type                     SimpleAggregateFunction(any, LowCardinality(String)),
+    geo_country_code                SimpleAggregateFunction(any, FixedString(2)),
+    worst_first_byte_duration       SimpleAggregateFunction(max, Nullable(UInt16)),
+    worst_first_contentful_paint    SimpleAggregateFunction(max, Nullable(UInt16)),
+    worst_largest_contentful_paint  SimpleAggregateFunction(max, Nullable(UInt16)),
+    worst_cumulative_layout_shift   SimpleAggregateFunction(max, Nullable(Float32)),
+    worst_first_input_delay         SimpleAggregateFunction(max, Nullable(UInt16)),
+    worst_interaction_to_next_paint SimpleAggregateFunction(max, Nullable(UInt16))
+)
+ENGINE = AggregatingMergeTree()
+PARTITION BY toYYYYMM(event_date)
+ORDER BY (hostname, session_id)
+SETTINGS index_granularity = 8192
+
+--migration:split
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS {prefix}webperf_rum_sessions_mv TO {prefix}webperf_rum_sessions AS
+SELECT
+    min(event_date) AS event_date,
+    hostname,
+    session_id,
+    min(created_at) AS start_time,
+    max(created_at) AS end_time,
+    countIf(event_type != 'quit_page') AS page_count,
+    argMinState(url, created_at) AS landing_url,
+    argMaxState(url, created_at) AS exit_url,
+    any(device_type) AS device_type,
+    any(geo_country_code) AS geo_country_code,
+    max(first_byte_duration) AS worst_first_byte_duration,
+    max(first_contentful_paint) AS worst_first_contentful_paint,
+    max(largest_contentful_paint) AS worst_largest_contentful_paint,
+    max(cumulative_layout_shift) AS worst_cumulative_layout_shift,
+    max(first_input_delay) AS worst_first_input_delay,
+    max(interaction_to_next_paint) AS worst_interaction_to_next_paint
+FROM {prefix}webperf_rum_events
+WHERE session_id != toFixedString('', 43)
+    AND event_type IN ('visit_page', 'quit_page', 'spa_hard', 'spa')
+GROUP BY hostname, session_id
+
+--migration:split
+
+CREATE VIEW IF NOT EXISTS {prefix}webperf_rum_sessions_summary AS
+SELECT
+    hostname,
+    session_id,
+    min(event_date) AS event_date,
+    min(start_time) AS start_time,
+    max(end_time) AS end_time,
+    dateDiff('second', start_time, end_time) AS duration,
+    sum(page_count) AS page_count,
+    page_count <= 1 AS bounce,
+    argMinMerge(landing_url) AS landing_url,
+    argMaxMerge(exit_url) AS exit_url,
+    any(device_type) AS device_type,
+    any(geo_country_code) AS geo_country_code,
+    max(worst_first_byte_duration) AS worst_first_byte_duration,
+    max(worst_first_contentful_paint) AS worst_first_contentful_paint,
+    max(worst_largest_contentful_paint) AS worst_largest_contentful_paint,
+    max(worst_cumulative_layout_shift) AS worst_cumulative_layout_shift,
+    max(worst_first_input_delay) AS worst_first_input_delay,
+    max(worst_interaction_to_next_paint) AS worst_interaction_to_next_paint
+FROM {prefix}webperf_rum_sessions
+GROUP BY hostname, session_id
\ No newline at end of file
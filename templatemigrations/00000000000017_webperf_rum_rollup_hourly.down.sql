@@ -0,0 +1,5 @@
+DROP VIEW IF EXISTS {prefix}webperf_rum_rollup_hourly_mv
+
+--migration:split
+
+DROP TABLE IF EXISTS {prefix}webperf_rum_rollup_hourly
\ No newline at end of file
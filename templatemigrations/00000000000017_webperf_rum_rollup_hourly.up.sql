@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {prefix}webperf_rum_rollup_hourly (
+    event_hour                DateTime,
+    hostname                  LowCardinality(String),
+    url_group                 String,
+    device_type               LowCardinality(String),
+    geo_country_code          FixedString(2),
+    page_views                SimpleAggregateFunction(sum, UInt64),
+    largest_contentful_paint  AggregateFunction(quantiles(0.5, 0.75, 0.95), Nullable(UInt16)),
+    first_contentful_paint    AggregateFunction(quantiles(0.5, 0.75, 0.95), Nullable(UInt16)),
+    first_byte_duration       AggregateFunction(quantiles(0.5, 0.75, 0.95), Nullable(UInt16)),
+    cumulative_layout_shift   AggregateFunction(quantiles(0.5, 0.75, 0.95), Nullable(Float32)),
+    interaction_to_next_paint AggregateFunction(quantiles(0.5, 0.75, 0.95), Nullable(UInt16))
+)
+ENGINE = AggregatingMergeTree()
+PARTITION BY toYYYYMM(event_hour)
+ORDER BY (hostname, event_hour, url_group, device_type, geo_country_code)
+SETTINGS index_granularity = 8192
\ No newline at end of file
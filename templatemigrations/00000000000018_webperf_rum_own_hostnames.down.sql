@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {prefix}webperf_rum_own_hostnames
\ No newline at end of file
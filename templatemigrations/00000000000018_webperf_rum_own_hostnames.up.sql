@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS {prefix}webperf_rum_own_hostnames (
+    username                        String,
+    hostname                        String,
+    subscription_id                 String,
+    subscription_expire_at          DateTime,
+    updated_at                      DateTime64(3) DEFAULT now()
+)
+ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY (hostname, username)
+SETTINGS index_granularity = 8192
\ No newline at end of file
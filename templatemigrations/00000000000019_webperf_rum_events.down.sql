@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events DROP COLUMN lcp_ttfb, DROP COLUMN lcp_resource_load_delay, DROP COLUMN lcp_resource_load_time, DROP COLUMN lcp_render_delay
\ No newline at end of file
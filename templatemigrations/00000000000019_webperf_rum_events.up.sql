@@ -0,0 +1 @@
+ALTER TABLE {prefix}webperf_rum_events ADD COLUMN lcp_ttfb Nullable(UInt16), ADD COLUMN lcp_resource_load_delay Nullable(UInt16), ADD COLUMN lcp_resource_load_time Nullable(UInt16), ADD COLUMN lcp_render_delay Nullable(UInt16)
\ No newline at end of file
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxErasureIDs limits the number of the sessions and the page views erased by one request
+	MaxErasureIDs      = 1000
+	maxErasureIDLength = 128
+)
+
+// ErasureState is the state of the erasure job
+type ErasureState string
+
+const (
+	// ErasureRunning is the job which removes the data
+	ErasureRunning ErasureState = "running"
+	// ErasureDone is the job which started the database mutations and removed the dead letter rows and the backup lines
+	ErasureDone ErasureState = "done"
+	// ErasureFailed is the job which stopped on error
+	ErasureFailed ErasureState = "failed"
+)
+
+// ErasureRequest contains the erased sessions and page views
+type ErasureRequest struct {
+	// SessionIDs are the Boomerang rt.si values of session_id column
+	SessionIDs []string `json:"session_ids,omitempty"`
+	// PageIDs are the Boomerang pid values of page_id column
+	PageIDs []string `json:"page_ids,omitempty"`
+}
+
+// Validate checks the number and the length of the erased ids
+func (r ErasureRequest) Validate() error {
+	count := len(r.SessionIDs) + len(r.PageIDs)
+	if count == 0 {
+		return errors.New("session ids or page ids are required")
+	}
+	if count > MaxErasureIDs {
+		return fmt.Errorf("too many ids[%v] maximum[%v]", count, MaxErasureIDs)
+	}
+	for _, id := range r.SessionIDs {
+		if id == "" || len(id) > maxErasureIDLength {
+			return fmt.Errorf("invalid session id[%v]", id)
+		}
+	}
+	for _, id := range r.PageIDs {
+		if id == "" || len(id) > maxErasureIDLength {
+			return fmt.Errorf("invalid page id[%v]", id)
+		}
+	}
+	return nil
+}
+
+// Matcher creates the matcher of the erased rows
+func (r ErasureRequest) Matcher() ErasureMatcher {
+	return ErasureMatcher{
+		sessionIDs: toSet(r.SessionIDs),
+		pageIDs:    toSet(r.PageIDs),
+	}
+}
+
+// ErasureMatcher matches the rows of the erased sessions and page views
+type ErasureMatcher struct {
+	sessionIDs map[string]struct{}
+	pageIDs    map[string]struct{}
+}
+
+// Matches checks if the row with the session id and the page id is erased
+func (m ErasureMatcher) Matches(sessionID, pageID string) bool {
+	if _, ok := m.sessionIDs[sessionID]; ok && sessionID != "" {
+		return true
+	}
+	_, ok := m.pageIDs[pageID]
+	return ok && pageID != ""
+}
+
+func toSet(values []string) map[string]struct{} {
+	result := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		result[value] = struct{}{}
+	}
+	return result
+}
+
+// ErasureStatus contains the erasure progress of one table
+type ErasureStatus struct {
+	Table string `json:"table"`
+	// RemainingRows is the number of the erased rows which are not deleted yet
+	RemainingRows uint64 `json:"remaining_rows"`
+	// PendingMutations is the number of the table mutations which are not finished
+	PendingMutations uint64 `json:"pending_mutations"`
+	FailReason       string `json:"fail_reason,omitempty"`
+}
+
+// ErasureResult contains the started erasure
+type ErasureResult struct {
+	ErasureRequest
+	// BackupLines is the number of the lines removed from the backup files
+	BackupLines int `json:"backup_lines"`
+	// DeadLetterRows is the number of the rows removed from the dead letters
+	DeadLetterRows int `json:"dead_letter_rows"`
+}
+
+// ErasureJob is the erasure running in background
+type ErasureJob struct {
+	ID         string        `json:"id"`
+	State      ErasureState  `json:"state"`
+	Result     ErasureResult `json:"result"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
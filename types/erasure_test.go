@@ -0,0 +1,72 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErasureRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ErasureRequest
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			wantErr: "session ids or page ids are required",
+		},
+		{
+			name:    "sessions and pages",
+			request: ErasureRequest{SessionIDs: []string{"session1"}, PageIDs: []string{"page1"}},
+		},
+		{
+			name:    "pages",
+			request: ErasureRequest{PageIDs: []string{"page1"}},
+		},
+		{
+			name:    "too many ids",
+			request: ErasureRequest{SessionIDs: make([]string, MaxErasureIDs), PageIDs: []string{"page1"}},
+			wantErr: "too many ids[1001] maximum[1000]",
+		},
+		{
+			name:    "empty page id",
+			request: ErasureRequest{PageIDs: []string{""}},
+			wantErr: "invalid page id[]",
+		},
+		{
+			name:    "long session id",
+			request: ErasureRequest{SessionIDs: []string{strings.Repeat("a", 129)}},
+			wantErr: "invalid session id[" + strings.Repeat("a", 129) + "]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestErasureMatcher_Matches(t *testing.T) {
+	matcher := ErasureRequest{SessionIDs: []string{"session1"}, PageIDs: []string{"page1"}}.Matcher()
+	tests := []struct {
+		name      string
+		sessionID string
+		pageID    string
+		want      bool
+	}{
+		{name: "session", sessionID: "session1", pageID: "page2", want: true},
+		{name: "page", sessionID: "session2", pageID: "page1", want: true},
+		{name: "other", sessionID: "session2", pageID: "page2"},
+		{name: "empty", sessionID: "", pageID: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.Matches(tt.sessionID, tt.pageID); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxMetricsPeriodDays limits the period of the metrics query
+const maxMetricsPeriodDays = 93
+
+// MetricsFilter selects the page views of the metrics query
+type MetricsFilter struct {
+	Hostname string
+	From     time.Time
+	To       time.Time
+	// URLPattern matches the page url, * matches any characters
+	URLPattern  string
+	DeviceType  string
+	CountryCode string
+	BrowserName string
+}
+
+// Validate checks the required hostname and the period
+func (f MetricsFilter) Validate() error {
+	if f.Hostname == "" {
+		return errors.New("hostname is required")
+	}
+	if !f.From.Before(f.To) {
+		return fmt.Errorf("from[%v] must be before to[%v]", f.From.Format(time.RFC3339), f.To.Format(time.RFC3339))
+	}
+	if f.To.After(f.From.AddDate(0, 0, maxMetricsPeriodDays)) {
+		return fmt.Errorf("period from[%v] to[%v] is longer than %v days", f.From.Format(time.RFC3339), f.To.Format(time.RFC3339), maxMetricsPeriodDays)
+	}
+	return nil
+}
+
+// Metrics contains the Web Vitals of the filtered page views
+type Metrics struct {
+	Hostname  string         `json:"hostname"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	PageViews uint64         `json:"page_views"`
+	Vitals    []VitalMetrics `json:"vitals"`
+}
+
+// VitalMetrics contains the percentiles and the rating shares of one Web Vital
+// The percentiles are nil when there are no samples
+type VitalMetrics struct {
+	Name             string   `json:"name"`
+	Samples          uint64   `json:"samples"`
+	P50              *float64 `json:"p50"`
+	P75              *float64 `json:"p75"`
+	P95              *float64 `json:"p95"`
+	Good             float64  `json:"good"`
+	NeedsImprovement float64  `json:"needs_improvement"`
+	Poor             float64  `json:"poor"`
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricsFilter_Validate(t *testing.T) {
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		filter  MetricsFilter
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			filter: MetricsFilter{Hostname: "www.example.com", From: from, To: from.AddDate(0, 0, 93)},
+		},
+		{
+			name:    "missing hostname",
+			filter:  MetricsFilter{From: from, To: from.Add(time.Hour)},
+			wantErr: "hostname is required",
+		},
+		{
+			name:    "empty period",
+			filter:  MetricsFilter{Hostname: "www.example.com", From: from, To: from},
+			wantErr: "from[2023-05-01T00:00:00Z] must be before to[2023-05-01T00:00:00Z]",
+		},
+		{
+			name:    "too long period",
+			filter:  MetricsFilter{Hostname: "www.example.com", From: from, To: from.AddDate(0, 0, 94)},
+			wantErr: "period from[2023-05-01T00:00:00Z] to[2023-08-03T00:00:00Z] is longer than 93 days",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("MetricsFilter.Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("MetricsFilter.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
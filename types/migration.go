@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// MigrationStatus is the state of the database migration
+type MigrationStatus struct {
+	Name       string     `json:"name"`
+	Applied    bool       `json:"applied"`
+	MigratedAt *time.Time `json:"migrated_at,omitempty"`
+	// SQL is the migration with the table prefix, it is set by the dry run
+	SQL string `json:"sql,omitempty"`
+}
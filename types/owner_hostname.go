@@ -61,3 +61,12 @@ func NewOwnerHostname(
 		Subscription: subscription,
 	}
 }
+
+// SeenHostname is the hostname which sent the beacons
+type SeenHostname struct {
+	Hostname string    `json:"hostname"`
+	LastSeen time.Time `json:"last_seen"`
+	// Events is the number of the events in the counted days
+	Events     uint64 `json:"events"`
+	Registered bool   `json:"registered"`
+}
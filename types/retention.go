@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nolint: gochecknoglobals
+var retentionHostnamePattern = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+// Retention contains the number of days the events are kept, 0 keeps the events forever
+type Retention struct {
+	Days int
+	// Hostnames overrides the days by hostname
+	Hostnames map[string]int
+}
+
+// ParseRetention parses the global days and the hostname overrides "www.example.com=30;shop.example.com=365"
+func ParseRetention(days int, hostnames string) (Retention, error) {
+	if days < 0 {
+		return Retention{}, fmt.Errorf("invalid retention days[%v]", days)
+	}
+	result := Retention{Days: days, Hostnames: map[string]int{}}
+	for _, entry := range strings.Split(hostnames, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		hostname, value, found := strings.Cut(entry, "=")
+		hostname = strings.TrimSpace(hostname)
+		if !found || !retentionHostnamePattern.MatchString(hostname) {
+			return Retention{}, fmt.Errorf("invalid retention hostname entry[%v]", entry)
+		}
+		hostnameDays, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || hostnameDays <= 0 {
+			return Retention{}, fmt.Errorf("invalid retention days entry[%v]", entry)
+		}
+		result.Hostnames[hostname] = hostnameDays
+	}
+	return result, nil
+}
+
+// Enabled checks if any events expire
+func (r Retention) Enabled() bool {
+	return r.Days > 0 || len(r.Hostnames) > 0
+}
+
+// SortedHostnames returns the override hostnames in alphabetical order
+func (r Retention) SortedHostnames() []string {
+	result := make([]string, 0, len(r.Hostnames))
+	for hostname := range r.Hostnames {
+		result = append(result, hostname)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// RetentionPartition contains the rows of the table partition which expire
+type RetentionPartition struct {
+	Table       string `json:"table"`
+	Partition   string `json:"partition"`
+	Rows        uint64 `json:"rows"`
+	ExpiredRows uint64 `json:"expired_rows"`
+	// Removed is true when all the partition rows expire
+	Removed bool `json:"removed"`
+}
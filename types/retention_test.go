@@ -0,0 +1,59 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		days      int
+		hostnames string
+		want      Retention
+		wantErr   bool
+	}{
+		{
+			name: "disabled",
+			want: Retention{Hostnames: map[string]int{}},
+		},
+		{
+			name:      "days with hostnames",
+			days:      90,
+			hostnames: " www.example.com = 30; shop.example.com=365;",
+			want:      Retention{Days: 90, Hostnames: map[string]int{"www.example.com": 30, "shop.example.com": 365}},
+		},
+		{
+			name:    "negative days",
+			days:    -1,
+			wantErr: true,
+		},
+		{
+			name:      "missing days",
+			hostnames: "www.example.com",
+			wantErr:   true,
+		},
+		{
+			name:      "zero hostname days",
+			hostnames: "www.example.com=0",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid hostname",
+			hostnames: "www.example.com'=30",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRetention(tt.days, tt.hostnames)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRetention() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRetention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
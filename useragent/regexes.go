@@ -0,0 +1,83 @@
+package useragent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+const versionLength = 12
+
+// validationSample is user agent which must be recognized by valid regular expressions
+type validationSample struct {
+	userAgent string
+	family    string
+}
+
+// nolint: gochecknoglobals
+var validationSamples = []validationSample{
+	{
+		userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/104.0.5112.102 Safari/537.36",
+		family:    "Chrome",
+	},
+	{
+		userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 13_2_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0.3 Mobile/15E148 Safari/604.1",
+		family:    "Mobile Safari",
+	},
+}
+
+// Regexes is validated uap-go parser with the version of the regular expressions
+type Regexes struct {
+	Parser  *uaparser.Parser
+	Version string
+	Source  string
+}
+
+// Load compiles and validates uap-go regular expressions
+// The source describes where the regular expressions come from (embedded or file path)
+func Load(data []byte, source string) (*Regexes, error) {
+	parser, err := compile(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile user agent regexes source[%v] err[%w]", source, err)
+	}
+	if err := validate(parser); err != nil {
+		return nil, fmt.Errorf("invalid user agent regexes source[%v] err[%w]", source, err)
+	}
+	return &Regexes{
+		Parser:  parser,
+		Version: Version(data),
+		Source:  source,
+	}, nil
+}
+
+// Version returns short sha256 checksum of the regular expressions
+func Version(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:versionLength]
+}
+
+// compile recovers from the panic of uap-go when some of the regular expressions are invalid
+func compile(data []byte) (parser *uaparser.Parser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return uaparser.NewFromBytes(data)
+}
+
+func validate(parser *uaparser.Parser) error {
+	if len(parser.UA) == 0 || len(parser.OS) == 0 || len(parser.Device) == 0 {
+		return errors.New("user_agent_parsers, os_parsers and device_parsers are required")
+	}
+	for _, sample := range validationSamples {
+		family := parser.ParseUserAgent(sample.userAgent).Family
+		if family != sample.family {
+			return fmt.Errorf("expected family[%v] got[%v] for user agent[%v]", sample.family, family, sample.userAgent)
+		}
+	}
+	return nil
+}
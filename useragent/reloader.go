@@ -0,0 +1,99 @@
+package useragent
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/basicrum/front_basicrum_go/beacon"
+)
+
+// LoadFile reads, compiles and validates uap-go regular expressions file
+func LoadFile(path string) (*Regexes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read user agent regexes file[%v] err[%w]", path, err)
+	}
+	return Load(data, path)
+}
+
+// Reloader reloads the user agent regular expressions from file periodically and on SIGHUP
+type Reloader struct {
+	path     string
+	interval time.Duration
+	parser   *beacon.RegexUserAgentParser
+	version  string
+	onReload func()
+}
+
+// NewReloader creates regular expressions reloader
+// The interval zero disables the periodic reload. The onReload is called after the parser is swapped
+func NewReloader(
+	path string,
+	interval time.Duration,
+	parser *beacon.RegexUserAgentParser,
+	version string,
+	onReload func(),
+) *Reloader {
+	return &Reloader{
+		path:     path,
+		interval: interval,
+		parser:   parser,
+		version:  version,
+		onReload: onReload,
+	}
+}
+
+// Reload loads the regular expressions file and swaps the parser when the file is valid and changed
+func (r *Reloader) Reload() (bool, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return false, fmt.Errorf("cannot read user agent regexes file[%v] err[%w]", r.path, err)
+	}
+	if Version(data) == r.version {
+		return false, nil
+	}
+	regexes, err := Load(data, r.path)
+	if err != nil {
+		return false, err
+	}
+	r.parser.Swap(regexes.Parser)
+	r.version = regexes.Version
+	if r.onReload != nil {
+		r.onReload()
+	}
+	log.Printf("user agent regexes reloaded version[%v] source[%v]", regexes.Version, regexes.Source)
+	return true, nil
+}
+
+// Run reloads the regular expressions on SIGHUP and on every interval
+func (r *Reloader) Run() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-hangup:
+			r.reload()
+		case <-tick:
+			r.reload()
+		}
+	}
+}
+
+func (r *Reloader) reload() {
+	if _, err := r.Reload(); err != nil {
+		log.Printf("user agent regexes reload failed, keeping version[%v] err[%v]", r.version, err)
+	}
+}
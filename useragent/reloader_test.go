@@ -0,0 +1,140 @@
+package useragent
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/basicrum/front_basicrum_go/beacon"
+	"github.com/basicrum/front_basicrum_go/testhelper"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chromeUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/104.0.5112.102 Safari/537.36"
+	// minimalRegexes recognizes only the validation samples
+	minimalRegexes = `
+user_agent_parsers:
+  - regex: '(Chrome)/(\d+)\.(\d+)\.(\d+)'
+  - regex: 'Mobile/\w+ Safari'
+    family_replacement: 'Mobile Safari'
+os_parsers:
+  - regex: '(Windows) NT'
+device_parsers:
+  - regex: '(iPhone)'
+`
+	// invalidRegexes contains regex which cannot be compiled
+	invalidRegexes = `
+user_agent_parsers:
+  - regex: '(Chrome'
+os_parsers:
+  - regex: '(Windows) NT'
+device_parsers:
+  - regex: '(iPhone)'
+`
+	// unrecognizedRegexes does not recognize the validation samples
+	unrecognizedRegexes = `
+user_agent_parsers:
+  - regex: '(Firefox)/(\d+)'
+os_parsers:
+  - regex: '(Windows) NT'
+device_parsers:
+  - regex: '(iPhone)'
+`
+)
+
+func readProjectRegexes(t *testing.T) []byte {
+	data, err := os.ReadFile(path.Join(testhelper.GetProjectRoot(), "assets", "uaparser_regexes.yaml"))
+	require.NoError(t, err)
+	return data
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{
+			name: "project regexes",
+			data: readProjectRegexes(t),
+		},
+		{
+			name: "minimal regexes",
+			data: []byte(minimalRegexes),
+		},
+		{
+			name:    "invalid yaml",
+			data:    []byte("user_agent_parsers: ["),
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			data:    []byte(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			data:    []byte(invalidRegexes),
+			wantErr: true,
+		},
+		{
+			name:    "validation samples are not recognized",
+			data:    []byte(unrecognizedRegexes),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.data, "test")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, Version(tt.data), got.Version)
+			require.Equal(t, "test", got.Source)
+			require.Len(t, got.Version, versionLength)
+		})
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	regexesPath := filepath.Join(t.TempDir(), "regexes.yaml")
+	projectRegexes := readProjectRegexes(t)
+	require.NoError(t, os.WriteFile(regexesPath, projectRegexes, 0o600))
+
+	regexes, err := LoadFile(regexesPath)
+	require.NoError(t, err)
+	parser := beacon.NewUserAgentParser(regexes.Parser)
+	reloads := 0
+	r := NewReloader(regexesPath, 0, parser, regexes.Version, func() { reloads++ })
+
+	// unchanged file
+	reloaded, err := r.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+	require.Equal(t, "10", parser.Parse(chromeUserAgent).Client.Os.Major)
+
+	// invalid file keeps the previous parser
+	require.NoError(t, os.WriteFile(regexesPath, []byte(invalidRegexes), 0o600))
+	reloaded, err = r.Reload()
+	require.Error(t, err)
+	require.False(t, reloaded)
+	require.Equal(t, "10", parser.Parse(chromeUserAgent).Client.Os.Major)
+
+	// changed file swaps the parser
+	require.NoError(t, os.WriteFile(regexesPath, []byte(minimalRegexes), 0o600))
+	reloaded, err = r.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+	require.Equal(t, 1, reloads)
+	require.Equal(t, "", parser.Parse(chromeUserAgent).Client.Os.Major)
+	require.Equal(t, "Chrome", parser.Parse(chromeUserAgent).Client.UserAgent.Family)
+
+	// missing file
+	require.NoError(t, os.Remove(regexesPath))
+	_, err = r.Reload()
+	require.Error(t, err)
+}